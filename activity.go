@@ -0,0 +1,140 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// activityTask describes the task an activity entry is about as it is now.
+// Title and Status are empty if the task has since been purged.
+type activityTask struct {
+	ID      int    `json:"id"`
+	Title   string `json:"title,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+type activityEntry struct {
+	ID        int64           `json:"id"`
+	Task      activityTask    `json:"task"`
+	Action    string          `json:"action"`
+	Actor     string          `json:"actor"`
+	Detail    json.RawMessage `json:"detail,omitempty"`
+	CreatedAt string          `json:"created_at"`
+}
+
+// getActivity is the cross-task feed for dashboards: audit entries for the
+// caller's tasks, or every task for admins, newest first, optionally
+// narrowed to one actor or task. Updates that moved a task into a done
+// status are reported as "completed". Entries for purged tasks have no
+// owner left to check, so only admins see them.
+func getActivity(c *gin.Context) {
+	var (
+		conds []string
+		args  []any
+	)
+	if actor := c.Query("actor"); actor != "" {
+		conds = append(conds, "a.actor = ?")
+		args = append(args, actor)
+	}
+	if raw := c.Query("task_id"); raw != "" {
+		taskID, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid task ID",
+			})
+			return
+		}
+		conds = append(conds, "a.task_id = ?")
+		args = append(args, taskID)
+	}
+	if owned, ownerArgs := ownerCondition(c, "t."); owned != "" {
+		conds = append(conds, owned)
+		args = append(args, ownerArgs...)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	page, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var total int
+	if err := db.QueryRowContext(c.Request.Context(), "SELECT COUNT(*) FROM task_audit a LEFT JOIN tasks t ON t.id = a.task_id"+where, args...).Scan(&total); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count activity",
+		})
+		return
+	}
+
+	wf := currentWorkflow()
+	doneArgs := make([]any, len(wf.Done))
+	for i, s := range wf.Done {
+		doneArgs[i] = s
+	}
+	rows, err := db.QueryContext(c.Request.Context(), `SELECT a.id, a.task_id,
+			CASE WHEN a.action = 'updated' AND json_extract(a.detail, '$.status.to') IN (`+placeholders(len(doneArgs))+`)
+				THEN 'completed' ELSE a.action END,
+			a.actor, COALESCE(a.detail, ''), a.created_at, t.title, t.status, t.deleted_at IS NOT NULL
+		FROM task_audit a LEFT JOIN tasks t ON t.id = a.task_id`+where+`
+		ORDER BY a.id DESC LIMIT ? OFFSET ?`,
+		append(append(doneArgs, args...), page.Limit, page.Offset)...)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch activity",
+		})
+		return
+	}
+	defer rows.Close()
+
+	entries := []activityEntry{}
+	for rows.Next() {
+		var (
+			e       activityEntry
+			detail  string
+			title   sql.NullString
+			status  sql.NullString
+			deleted sql.NullBool
+		)
+		if err := rows.Scan(&e.ID, &e.Task.ID, &e.Action, &e.Actor, &detail, &e.CreatedAt, &title, &status, &deleted); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan activity",
+			})
+			return
+		}
+		if detail != "" {
+			e.Detail = json.RawMessage(detail)
+		}
+		e.Task.Title, e.Task.Status, e.Task.Deleted = title.String, status.String, deleted.Bool
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch activity",
+		})
+		return
+	}
+
+	setTotalCount(c, total)
+	respondList(c, entries, pageMeta(total, page))
+}
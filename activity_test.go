@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func getTestActivity(t *testing.T, router http.Handler, query string, headers ...string) []activityEntry {
+	t.Helper()
+	w := doRequest(router, http.MethodGet, "/activity"+query, "", headers...)
+	expectStatus(t, w, http.StatusOK)
+	var entries []activityEntry
+	decodeBody(t, w, &entries)
+	return entries
+}
+
+func TestGetActivity(t *testing.T) {
+	router := newTestServer(t, "ADMIN_TOKEN=secret")
+	admin := "Authorization: Bearer secret"
+	task := createTestTask(t, router, `{"title":"feed"}`, "X-User: alice")
+	doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"priority":2}`, "X-User: bob")
+	doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"status":"done"}`, "X-User: bob")
+	gone := createTestTask(t, router, `{"title":"gone"}`, "X-User: alice")
+	doRequest(router, http.MethodDelete, taskLocation(gone.ID), "", "X-User: alice")
+
+	entries := getTestActivity(t, router, "", admin)
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	if want := []string{"deleted", "created", "completed", "updated", "created"}; !slices.Equal(actions, want) {
+		t.Fatalf("actions = %v, want %v newest first", actions, want)
+	}
+	if e := entries[0]; e.Task.ID != gone.ID || !e.Task.Deleted || e.Task.Title != "gone" {
+		t.Errorf("deleted entry task = %+v, want the deleted task", e.Task)
+	}
+	if e := entries[2]; e.Actor != "bob" || e.Task.Status != "done" {
+		t.Errorf("completed entry = %+v, want bob's completion", e)
+	}
+
+	if got := getTestActivity(t, router, "?actor=bob", admin); len(got) != 2 {
+		t.Errorf("bob's activity = %d entries, want 2", len(got))
+	}
+	if got := getTestActivity(t, router, "?actor=alice&limit=1&offset=1", admin); len(got) != 1 || got[0].Action != "created" || got[0].Task.ID != gone.ID {
+		t.Errorf("alice's second entry = %+v, want the second create", got)
+	}
+
+	w := doRequest(router, http.MethodGet, "/activity?task_id=abc", "")
+	expectStatus(t, w, http.StatusBadRequest)
+}
+
+func TestGetActivityScopedToOwner(t *testing.T) {
+	router := newTestServer(t)
+	mine := createTestTask(t, router, `{"title":"mine"}`, "X-User: alice")
+	theirs := createTestTask(t, router, `{"title":"theirs"}`, "X-User: bob")
+	// Bob's edit of Alice's task is part of Alice's feed.
+	doRequest(router, http.MethodPatch, taskLocation(mine.ID), `{"priority":2}`, "X-User: bob")
+
+	w := doRequest(router, http.MethodGet, "/activity", "", "X-User: alice")
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Header().Get("X-Total-Count"); got != "2" {
+		t.Errorf("X-Total-Count = %q, want 2", got)
+	}
+	var entries []activityEntry
+	decodeBody(t, w, &entries)
+	for _, e := range entries {
+		if e.Task.ID != mine.ID {
+			t.Errorf("alice's feed has entry %+v for another owner's task", e)
+		}
+	}
+	if len(entries) != 2 {
+		t.Errorf("alice's feed = %d entries, want 2", len(entries))
+	}
+
+	if got := getTestActivity(t, router, "?task_id="+strconv.Itoa(theirs.ID), "X-User: alice"); len(got) != 0 {
+		t.Errorf("alice sees %d entries for bob's task, want none", len(got))
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireAdmin guards admin routes with the bearer token in ADMIN_TOKEN.
+// When no token is configured the admin API is disabled entirely.
+func requireAdmin() gin.HandlerFunc {
+	token := os.Getenv("ADMIN_TOKEN")
+
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "admin API is disabled",
+			})
+			return
+		}
+
+		if !hasAdminToken(c, token) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "admin token required",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func hasAdminToken(c *gin.Context, token string) bool {
+	given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// ownerCondition limits a query on tasks, aliased by prefix (such as "t."),
+// to those the caller may see: tasks they own and tasks with no owner,
+// matching canManage. Admins see every task and get an empty condition.
+func ownerCondition(c *gin.Context, prefix string) (string, []any) {
+	if hasAdminToken(c, os.Getenv("ADMIN_TOKEN")) {
+		return "", nil
+	}
+	return "(" + prefix + "owner = '' OR " + prefix + "owner = ?)", []any{requestActor(c)}
+}
+
+type reassignOwnerRequest struct {
+	From               string `json:"from"`
+	To                 string `json:"to"`
+	IncludeAssignments bool   `json:"include_assignments"`
+}
+
+// reassignOwner moves every task owned by one user to another, for example
+// when someone leaves the team. With include_assignments the tasks assigned
+// to that user are handed over too.
+func reassignOwner(c *gin.Context) {
+	var req reassignOwnerRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	req.From = strings.TrimSpace(req.From)
+	req.To = strings.TrimSpace(req.To)
+	if req.From == "" || req.To == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "from and to are required",
+		})
+		return
+	}
+	if req.From == req.To {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "from and to must be different users",
+		})
+		return
+	}
+
+	tx, err := db.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	actor := requestActor(c)
+	owned, err := reassignColumn(tx, "owner", req.From, req.To, actor)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to reassign tasks",
+		})
+		return
+	}
+
+	assigned := 0
+	if req.IncludeAssignments {
+		assigned, err = reassignColumn(tx, "assignee", req.From, req.To, actor)
+		if err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to reassign assignments",
+			})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to commit transaction",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reassigned":             owned,
+		"assignments_reassigned": assigned,
+	})
+}
+
+// reassignColumn changes column from one user to another on every matching
+// task, writing an audit entry for each. column must be a trusted name.
+func reassignColumn(tx queryExecer, column, from, to, actor string) (int, error) {
+	rows, err := tx.Query("SELECT id FROM tasks WHERE "+column+" = ? AND deleted_at IS NULL", from)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.Exec("UPDATE tasks SET "+column+" = ?, updated_at = ? WHERE "+column+" = ? AND deleted_at IS NULL",
+		to, formatDBTime(time.Now()), from); err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		detail := map[string]fieldChange{column: {from, to}}
+		if err := recordAudit(tx, id, "reassigned", actor, detail); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), nil
+}
+
+// getDBStats reports connection pool statistics and open streaming connections.
+func getDBStats(c *gin.Context) {
+	c.JSON(http.StatusOK, dbStats())
+}
+
+func dbStats() gin.H {
+	stats := db.Stats()
+	return gin.H{
+		"open_connections":    stats.OpenConnections,
+		"in_use":              stats.InUse,
+		"idle":                stats.Idle,
+		"wait_count":          stats.WaitCount,
+		"wait_duration_ms":    stats.WaitDuration.Milliseconds(),
+		"max_idle_closed":     stats.MaxIdleClosed,
+		"max_lifetime_closed": stats.MaxLifetimeClosed,
+		"stream_connections":  streamConnections.Load(),
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestReassignOwner(t *testing.T) {
+	router := newTestServer(t, "ADMIN_TOKEN=secret")
+	var owned []Task
+	for _, title := range []string{"one", "two", "three"} {
+		owned = append(owned, createTestTask(t, router, `{"title":"`+title+`","assignee":"alice"}`, "X-User: alice"))
+	}
+	other := createTestTask(t, router, `{"title":"carol's"}`, "X-User: carol")
+	auth := "Authorization: Bearer secret"
+
+	w := doRequest(router, http.MethodPost, "/admin/reassign-owner", `{"from":"alice","to":"bob"}`)
+	expectStatus(t, w, http.StatusUnauthorized)
+	w = doRequest(router, http.MethodPost, "/admin/reassign-owner", `{"from":"alice","to":"alice"}`, auth)
+	expectStatus(t, w, http.StatusBadRequest)
+
+	w = doRequest(router, http.MethodPost, "/admin/reassign-owner", `{"from":"alice","to":"bob","include_assignments":true}`, auth)
+	expectStatus(t, w, http.StatusOK)
+	var resp struct {
+		Reassigned            int `json:"reassigned"`
+		AssignmentsReassigned int `json:"assignments_reassigned"`
+	}
+	decodeBody(t, w, &resp)
+	if resp.Reassigned != 3 || resp.AssignmentsReassigned != 3 {
+		t.Fatalf("response = %+v, want 3 of each", resp)
+	}
+
+	for _, task := range owned {
+		got := getTestTask(t, router, task.ID)
+		if got.Owner != "bob" || got.Assignee != "bob" {
+			t.Errorf("task %d owner/assignee = %q/%q, want bob", task.ID, got.Owner, got.Assignee)
+		}
+		if actions := auditActions(t, router, task.ID); !slices.Contains(actions, "reassigned") {
+			t.Errorf("task %d audit = %v, want a reassigned entry", task.ID, actions)
+		}
+	}
+	if got := getTestTask(t, router, other.ID); got.Owner != "carol" {
+		t.Errorf("unrelated task owner = %q, want carol", got.Owner)
+	}
+}
+
+func TestAdminDisabledWithoutToken(t *testing.T) {
+	router := newTestServer(t, "ADMIN_TOKEN=")
+	w := doRequest(router, http.MethodPost, "/admin/reassign-owner", `{"from":"a","to":"b"}`, "Authorization: Bearer ")
+	expectStatus(t, w, http.StatusForbidden)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const systemActor = "system"
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// requestActor identifies who made a request for the audit log.
+func requestActor(c *gin.Context) string {
+	if user := c.GetHeader("X-User"); user != "" {
+		return user
+	}
+	return "anonymous"
+}
+
+// recordAudit appends an entry to the task audit log. It is normally called
+// with the transaction that made the change so both commit together.
+func recordAudit(ex execer, taskID int, action, actor string, detail any) error {
+	var detailJSON []byte
+	if detail != nil {
+		var err error
+		detailJSON, err = json.Marshal(detail)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := ex.Exec("INSERT INTO task_audit (task_id, action, actor, detail, created_at) VALUES (?, ?, ?, ?, ?)",
+		taskID, action, actor, string(detailJSON), formatDBTime(time.Now()))
+	return err
+}
+
+type fieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// taskChanges lists the fields that differ between two versions of a task.
+func taskChanges(before, after Task) map[string]fieldChange {
+	changes := make(map[string]fieldChange)
+	if before.Title != after.Title {
+		changes["title"] = fieldChange{before.Title, after.Title}
+	}
+	if before.Status != after.Status {
+		changes["status"] = fieldChange{before.Status, after.Status}
+	}
+	if before.Priority != after.Priority {
+		changes["priority"] = fieldChange{before.Priority, after.Priority}
+	}
+	if before.Position != after.Position {
+		changes["position"] = fieldChange{before.Position, after.Position}
+	}
+	if before.Assignee != after.Assignee {
+		changes["assignee"] = fieldChange{before.Assignee, after.Assignee}
+	}
+	if !sameTime(before.DueDate, after.DueDate) {
+		changes["due_date"] = fieldChange{before.DueDate, after.DueDate}
+	}
+	if !sameID(before.ParentID, after.ParentID) {
+		changes["parent_id"] = fieldChange{before.ParentID, after.ParentID}
+	}
+	if !slices.Equal(before.Tags, after.Tags) {
+		changes["tags"] = fieldChange{before.Tags, after.Tags}
+	}
+	if before.InferStatus != after.InferStatus {
+		changes["infer_status"] = fieldChange{before.InferStatus, after.InferStatus}
+	}
+	return changes
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+func sameID(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
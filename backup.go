@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	backupPrefix = "tasks-"
+	backupSuffix = ".db"
+
+	// The backup copies this many pages per step and pauses between steps,
+	// releasing the source lock so writers are only held up briefly.
+	backupStepPages = 128
+	backupStepPause = 10 * time.Millisecond
+)
+
+// backupConfig controls the scheduled backup job. The job is disabled
+// unless both Dir and Interval are set.
+type backupConfig struct {
+	Dir      string
+	Interval time.Duration
+	Keep     int
+}
+
+func loadBackupConfig() (backupConfig, error) {
+	cfg := backupConfig{
+		Dir:      os.Getenv("BACKUP_DIR"),
+		Interval: envDuration("BACKUP_INTERVAL", 0),
+		Keep:     envInt("BACKUP_KEEP", 7),
+	}
+	if cfg.Keep < 1 {
+		return cfg, errors.New("BACKUP_KEEP must be at least 1")
+	}
+	if cfg.Dir != "" && cfg.Interval > 0 {
+		if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+			return cfg, fmt.Errorf("create backup directory: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+func startBackupJob(cfg backupConfig) {
+	if cfg.Dir == "" || cfg.Interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			path, err := runScheduledBackup(cfg, time.Now())
+			if err != nil {
+				log.Printf("scheduled backup failed: %v", err)
+				continue
+			}
+			log.Printf("scheduled backup written to %s", path)
+		}
+	}()
+}
+
+// runScheduledBackup writes a timestamped backup into cfg.Dir and then
+// removes the oldest backups beyond cfg.Keep.
+func runScheduledBackup(cfg backupConfig, now time.Time) (string, error) {
+	name := backupPrefix + now.UTC().Format("20060102T150405Z") + backupSuffix
+	path := filepath.Join(cfg.Dir, name)
+
+	if err := backupDatabase(path); err != nil {
+		return "", err
+	}
+	if err := rotateBackups(cfg.Dir, cfg.Keep); err != nil {
+		return path, fmt.Errorf("rotate backups: %w", err)
+	}
+	return path, nil
+}
+
+// backupDatabase copies the live database to path using SQLite's online
+// backup API, so the copy is consistent even while requests keep writing.
+// It writes to a temporary file first so a failed backup never leaves a
+// partial file under the final name.
+func backupDatabase(path string) error {
+	tmpPath := path + ".tmp"
+	defer os.Remove(tmpPath)
+
+	dest, err := sql.Open("sqlite3", tmpPath)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	ctx := context.Background()
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+
+	srcConn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	err = destConn.Raw(func(destDriver any) error {
+		return srcConn.Raw(func(srcDriver any) error {
+			b, err := destDriver.(*sqlite3.SQLiteConn).Backup("main", srcDriver.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return err
+			}
+			for {
+				done, err := b.Step(backupStepPages)
+				if err != nil {
+					b.Close()
+					return err
+				}
+				if done {
+					return b.Finish()
+				}
+				time.Sleep(backupStepPause)
+			}
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := destConn.Close(); err != nil {
+		return err
+	}
+	if err := dest.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// rotateBackups deletes the oldest backups in dir so at most keep remain.
+// Backup names sort chronologically, so the oldest come first.
+func rotateBackups(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var backups []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() && strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, backupSuffix) {
+			backups = append(backups, name)
+		}
+	}
+	sort.Strings(backups)
+
+	for len(backups) > keep {
+		if err := os.Remove(filepath.Join(dir, backups[0])); err != nil {
+			return err
+		}
+		log.Printf("removed old backup %s", backups[0])
+		backups = backups[1:]
+	}
+	return nil
+}
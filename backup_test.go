@@ -0,0 +1,53 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunScheduledBackupRotates(t *testing.T) {
+	router := newTestServer(t)
+	createTestTask(t, router, `{"title":"keep me"}`)
+
+	cfg := backupConfig{Dir: t.TempDir(), Interval: time.Hour, Keep: 2}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var last string
+	for i := 0; i < 3; i++ {
+		path, err := runScheduledBackup(cfg, start.Add(time.Duration(i)*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		last = path
+	}
+
+	entries, err := os.ReadDir(cfg.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name() != "tasks-20260101T010000Z.db" {
+		t.Fatalf("backups = %v, want the two newest", entries)
+	}
+
+	backup, err := sql.Open("sqlite3", last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backup.Close()
+	var title string
+	if err := backup.QueryRow("SELECT title FROM tasks").Scan(&title); err != nil || title != "keep me" {
+		t.Fatalf("backup task = %q, %v; want the live task", title, err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(cfg.Dir, "*.tmp")); len(matches) > 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestLoadBackupConfigRejectsKeepBelowOne(t *testing.T) {
+	t.Setenv("BACKUP_KEEP", "0")
+	if _, err := loadBackupConfig(); err == nil {
+		t.Fatal("loadBackupConfig accepted BACKUP_KEEP=0")
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// taskFields lists the JSON field names a client may send for a task.
+var taskFields = map[string]bool{
+	"title":     true,
+	"status":    true,
+	"priority":  true,
+	"position":  true,
+	"assignee":  true,
+	"due_date":  true,
+	"parent_id": true,
+	"tags":      true,
+
+	"infer_status": true,
+}
+
+// fieldAliases maps deprecated JSON field names to their canonical task field.
+var fieldAliases map[string]string
+
+// loadFieldAliases reads TASK_FIELD_ALIASES, a comma-separated list of
+// alias=field pairs such as "state=status,name=title".
+func loadFieldAliases() (map[string]string, error) {
+	aliases := make(map[string]string)
+	raw := os.Getenv("TASK_FIELD_ALIASES")
+	if raw == "" {
+		return aliases, nil
+	}
+
+	for _, pair := range strings.Split(raw, ",") {
+		alias, field, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		alias, field = strings.TrimSpace(alias), strings.TrimSpace(field)
+		if !ok || alias == "" || field == "" {
+			return nil, fmt.Errorf("invalid alias %q, expected alias=field", pair)
+		}
+		if !taskFields[field] {
+			return nil, fmt.Errorf("alias %q targets unknown field %q", alias, field)
+		}
+		if taskFields[alias] {
+			return nil, fmt.Errorf("alias %q shadows an existing field", alias)
+		}
+		aliases[alias] = field
+	}
+
+	return aliases, nil
+}
+
+func initFieldAliases() error {
+	aliases, err := loadFieldAliases()
+	if err != nil {
+		return err
+	}
+	fieldAliases = aliases
+	return nil
+}
+
+var errEmptyBody = errors.New("request body required")
+
+var errUnsupportedMediaType = errors.New("request body must be JSON")
+
+// normalizeJSONBodies and requireJSONContentType are set from
+// NORMALIZE_JSON_BODIES and REQUIRE_JSON_CONTENT_TYPE at startup.
+var (
+	normalizeJSONBodies    = true
+	requireJSONContentType bool
+)
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// isJSONContentType accepts JSON media types in any case and with
+// parameters, such as "application/json; charset=utf-8" or
+// "application/merge-patch+json". A missing type is accepted too, while a
+// charset other than UTF-8 is not.
+func isJSONContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if charset, ok := params["charset"]; ok && !strings.EqualFold(charset, "utf-8") {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// readJSONBody reads the request body for binding. A leading UTF-8 byte
+// order mark is dropped unless NORMALIZE_JSON_BODIES is off, and with
+// REQUIRE_JSON_CONTENT_TYPE a non-empty body must not be declared as
+// another type. A missing body reads as empty.
+func readJSONBody(c *gin.Context) ([]byte, error) {
+	if c.Request.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	if normalizeJSONBodies {
+		body = bytes.TrimPrefix(body, utf8BOM)
+	}
+	if requireJSONContentType && len(bytes.TrimSpace(body)) > 0 && !isJSONContentType(c.GetHeader("Content-Type")) {
+		return nil, errUnsupportedMediaType
+	}
+	return body, nil
+}
+
+// recoverInputPanics is set from RECOVER_INPUT_PANICS at startup.
+var recoverInputPanics = true
+
+// inputPanicError reports a panic raised while parsing client input.
+type inputPanicError struct {
+	Value any
+}
+
+func (e *inputPanicError) Error() string {
+	return fmt.Sprintf("malformed input: %v", e.Value)
+}
+
+// recoverInputPanic is deferred by the parsing helpers. It turns a panic
+// carrying a parse error into an *inputPanicError in *err, so the client
+// gets a 400 naming the parse error instead of the 500 from gin's recovery.
+// Any other panic is a server bug and is raised again. Panics elsewhere in
+// a handler are not affected.
+func recoverInputPanic(err *error) {
+	if !recoverInputPanics {
+		return
+	}
+	r := recover()
+	if r == nil {
+		return
+	}
+	if !isParseError(r) {
+		panic(r)
+	}
+	log.Printf("recovered panic while parsing input: %v", r)
+	*err = &inputPanicError{Value: r}
+}
+
+// isParseError reports whether a recovered panic value is an error from
+// decoding client input.
+func isParseError(r any) bool {
+	err, ok := r.(error)
+	if !ok {
+		return false
+	}
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		numErr    *strconv.NumError
+		timeErr   *time.ParseError
+		dateErr   *dateFieldError
+	)
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.As(err, &numErr) ||
+		errors.As(err, &timeErr) || errors.As(err, &dateErr)
+}
+
+// bindJSON decodes the request body into v, returning errEmptyBody when the
+// body is missing or blank so callers can tell it apart from malformed JSON.
+func bindJSON(c *gin.Context, v any) (err error) {
+	defer recoverInputPanic(&err)
+	body, err := readJSONBody(c)
+	if err != nil {
+		return err
+	}
+	return decodeJSON(c, body, v)
+}
+
+func decodeJSON(c *gin.Context, body []byte, v any) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return errEmptyBody
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return c.ShouldBindJSON(v)
+}
+
+// respondBindError reports a bindJSON failure to the client.
+func respondBindError(c *gin.Context, err error) {
+	if errors.Is(err, errEmptyBody) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "request body required",
+		})
+		return
+	}
+	if errors.Is(err, errUnsupportedMediaType) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": errUnsupportedMediaType.Error(),
+		})
+		return
+	}
+	if errors.Is(err, errInvalidTaskID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errInvalidTaskID.Error(),
+		})
+		return
+	}
+	var dateErr *dateFieldError
+	if errors.As(err, &dateErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": dateErr.Error(),
+		})
+		return
+	}
+	var panicErr *inputPanicError
+	if errors.As(err, &panicErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "malformed input",
+			"detail": fmt.Sprint(panicErr.Value),
+		})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": "invalid JSON input",
+	})
+}
+
+// missingTaskFields lists required task fields that are absent or blank.
+// Status is only required when the caller replaces the whole task.
+func missingTaskFields(task Task, requireStatus bool) []string {
+	var missing []string
+	if strings.TrimSpace(task.Title) == "" {
+		missing = append(missing, "title")
+	}
+	if requireStatus && task.Status == "" {
+		missing = append(missing, "status")
+	}
+	return missing
+}
+
+// bindTaskJSON decodes a task from the request body after rewriting any
+// aliased field names to their canonical form. When the canonical name is
+// also present it wins. Requests that used an alias get a Deprecation header
+// listing the old names.
+func bindTaskJSON(c *gin.Context, task *Task) (err error) {
+	defer recoverInputPanic(&err)
+	body, err := readJSONBody(c)
+	if err != nil {
+		return err
+	}
+
+	if len(bytes.TrimSpace(body)) > 0 {
+		if len(fieldAliases) > 0 {
+			if body, err = rewriteAliases(c, body); err != nil {
+				return err
+			}
+		}
+		if body, err = normalizeDateFields(body); err != nil {
+			return err
+		}
+	}
+
+	return decodeJSON(c, body, task)
+}
+
+func rewriteAliases(c *gin.Context, body []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+
+	var used []string
+	for alias, field := range fieldAliases {
+		value, ok := fields[alias]
+		if !ok {
+			continue
+		}
+		delete(fields, alias)
+		if _, exists := fields[field]; !exists {
+			fields[field] = value
+		}
+		used = append(used, alias)
+	}
+	if len(used) == 0 {
+		return body, nil
+	}
+
+	sort.Strings(used)
+	c.Header("Deprecation", "true")
+	c.Header("X-Deprecated-Fields", strings.Join(used, ", "))
+
+	return json.Marshal(fields)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFieldAliases(t *testing.T) {
+	router := newTestServer(t, "TASK_FIELD_ALIASES=state=status,name=title")
+
+	w := doRequest(router, http.MethodPost, "/task", `{"name":"aliased","state":"in_progress"}`)
+	expectStatus(t, w, http.StatusCreated)
+	if got := w.Header().Get("X-Deprecated-Fields"); got != "name, state" {
+		t.Errorf("X-Deprecated-Fields = %q, want %q", got, "name, state")
+	}
+	var task Task
+	decodeBody(t, w, &task)
+	if task.Title != "aliased" || task.Status != "in_progress" {
+		t.Fatalf("task = %q/%q, want the aliased values", task.Title, task.Status)
+	}
+
+	// The canonical field wins when both are sent.
+	w = doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"state":"todo","status":"done"}`)
+	expectStatus(t, w, http.StatusOK)
+	if got := getTestTask(t, router, task.ID); got.Status != "done" {
+		t.Fatalf("status = %q, want done", got.Status)
+	}
+
+	w = doRequest(router, http.MethodPost, "/task", `{"title":"plain"}`)
+	expectStatus(t, w, http.StatusCreated)
+	if got := w.Header().Get("Deprecation"); got != "" {
+		t.Errorf("Deprecation = %q on a request without aliases", got)
+	}
+}
+
+func TestLoadFieldAliasesRejectsBadPairs(t *testing.T) {
+	for _, raw := range []string{"state", "state=nope", "title=status", "=status"} {
+		t.Setenv("TASK_FIELD_ALIASES", raw)
+		if _, err := loadFieldAliases(); err == nil {
+			t.Errorf("loadFieldAliases(%q) succeeded", raw)
+		}
+	}
+}
+
+func TestUpdateTaskBodyErrors(t *testing.T) {
+	router := newTestServer(t)
+	task := createTestTask(t, router, `{"title":"original"}`)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "empty", body: "", want: "request body required"},
+		{name: "blank", body: "  \n", want: "request body required"},
+		{name: "no fields", body: "{}", want: "missing required fields"},
+		{name: "malformed", body: `{"title":`, want: "invalid JSON input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(router, http.MethodPut, taskLocation(task.ID), tt.body, "Content-Type: application/json")
+			expectStatus(t, w, http.StatusBadRequest)
+			var resp struct {
+				Error  string   `json:"error"`
+				Fields []string `json:"fields"`
+			}
+			decodeBody(t, w, &resp)
+			if resp.Error != tt.want {
+				t.Errorf("error = %q, want %q", resp.Error, tt.want)
+			}
+			if tt.body == "{}" && strings.Join(resp.Fields, ",") != "title,status" {
+				t.Errorf("fields = %v, want title and status", resp.Fields)
+			}
+		})
+	}
+
+	if got := getTestTask(t, router, task.ID); got.Title != "original" {
+		t.Errorf("title = %q after rejected updates", got.Title)
+	}
+}
+
+func TestRecoverInputPanic(t *testing.T) {
+	output := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(output) })
+	defaultErrorWriter := gin.DefaultErrorWriter
+	gin.DefaultErrorWriter = io.Discard
+	t.Cleanup(func() { gin.DefaultErrorWriter = defaultErrorWriter })
+
+	parse := func(v any) (err error) {
+		defer recoverInputPanic(&err)
+		panic(v)
+	}
+	_, numErr := strconv.Atoi("x")
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.POST("/parse", func(c *gin.Context) {
+		if err := parse(numErr); err != nil {
+			respondBindError(c, err)
+		}
+	})
+	router.POST("/bug", func(c *gin.Context) {
+		if err := parse("nil map"); err != nil {
+			respondBindError(c, err)
+		}
+	})
+
+	w := doRequest(router, http.MethodPost, "/parse", "")
+	expectStatus(t, w, http.StatusBadRequest)
+	var body map[string]string
+	decodeBody(t, w, &body)
+	if body["error"] != "malformed input" || body["detail"] != numErr.Error() {
+		t.Errorf("body = %v, want malformed input with detail %q", body, numErr.Error())
+	}
+	expectStatus(t, doRequest(router, http.MethodPost, "/bug", ""), http.StatusInternalServerError)
+
+	recoverInputPanics = false
+	t.Cleanup(func() { recoverInputPanics = true })
+	expectStatus(t, doRequest(router, http.MethodPost, "/parse", ""), http.StatusInternalServerError)
+}
+
+func TestIsParseError(t *testing.T) {
+	_, numErr := strconv.Atoi("x")
+	_, timeErr := time.Parse(time.RFC3339, "soon")
+	for _, tt := range []struct {
+		value any
+		want  bool
+	}{
+		{numErr, true},
+		{timeErr, true},
+		{fmt.Errorf("decoding tags: %w", &json.SyntaxError{}), true},
+		{errors.New("database is locked"), false},
+		{"index out of range", false},
+	} {
+		if got := isParseError(tt.value); got != tt.want {
+			t.Errorf("isParseError(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestIsJSONContentType(t *testing.T) {
+	for contentType, want := range map[string]bool{
+		"":                                 true,
+		"application/json":                 true,
+		"Application/JSON; charset=UTF-8":  true,
+		"application/merge-patch+json":     true,
+		"application/json; charset=latin1": false,
+		"text/plain":                       false,
+		"application/json; =":              false,
+	} {
+		if got := isJSONContentType(contentType); got != want {
+			t.Errorf("isJSONContentType(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+}
+
+func TestJSONBodyNormalization(t *testing.T) {
+	router := newTestServer(t)
+	t.Cleanup(func() { normalizeJSONBodies, requireJSONContentType = true, false })
+	bom := "\xef\xbb\xbf"
+
+	w := doRequest(router, http.MethodPost, "/task", bom+`{"title":"bom"}`, "Content-Type: application/json; charset=utf-8")
+	expectStatus(t, w, http.StatusCreated)
+	var task Task
+	decodeBody(t, w, &task)
+	if task.Title != "bom" {
+		t.Errorf("title = %q, want %q", task.Title, "bom")
+	}
+
+	requireJSONContentType = true
+	w = doRequest(router, http.MethodPost, "/task", `{"title":"plain"}`, "Content-Type: text/plain")
+	expectStatus(t, w, http.StatusUnsupportedMediaType)
+	w = doRequest(router, http.MethodPost, "/task", bom+`{"title":"bom"}`, "Content-Type: application/json; charset=utf-8")
+	expectStatus(t, w, http.StatusCreated)
+
+	normalizeJSONBodies = false
+	w = doRequest(router, http.MethodPost, "/task", bom+`{"title":"bom"}`)
+	expectStatus(t, w, http.StatusBadRequest)
+}
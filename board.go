@@ -0,0 +1,100 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getTaskContext returns a task with its neighbours in its status column,
+// ordered by position and then id. The usual list filters, such as
+// ?assignee=, narrow which tasks count as neighbours.
+func getTaskContext(c *gin.Context) {
+	taskID, ok := taskIDParam(c)
+	if !ok {
+		return
+	}
+
+	filter, err := taskFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	task, err := fetchTask(requestDB(c), taskID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "task not found",
+			})
+		} else {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to fetch task",
+			})
+		}
+		return
+	}
+
+	filter.Status = task.Status
+	filter.IncludeDeleted = false
+
+	prev, err := columnNeighbour(requestDB(c), filter, task, false)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch previous task",
+		})
+		return
+	}
+	next, err := columnNeighbour(requestDB(c), filter, task, true)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch next task",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"task":     task,
+		"previous": prev,
+		"next":     next,
+	})
+}
+
+// columnNeighbour finds the task immediately after (or before) task in the
+// column selected by filter, or nil at either end of the column.
+func columnNeighbour(q queryer, filter taskFilter, task Task, after bool) (*Task, error) {
+	where, args := filter.where()
+
+	cond, order := " AND (position < ? OR (position = ? AND id < ?))", " ORDER BY position DESC, id DESC"
+	if after {
+		cond, order = " AND (position > ? OR (position = ? AND id > ?))", " ORDER BY position, id"
+	}
+	args = append(args, task.Position, task.Position, task.ID)
+
+	var neighbour Task
+	err := scanTask(q.QueryRow("SELECT "+taskColumns+" FROM tasks"+where+cond+order+" LIMIT 1", args...), &neighbour)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []Task{neighbour}
+	if err := attachTags(q, tasks); err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetTaskContext(t *testing.T) {
+	router := newTestServer(t)
+	first := createTestTask(t, router, `{"title":"first","assignee":"alice"}`)
+	middle := createTestTask(t, router, `{"title":"middle","assignee":"bob"}`)
+	createTestTask(t, router, `{"title":"elsewhere","status":"done","assignee":"alice"}`)
+	last := createTestTask(t, router, `{"title":"last","assignee":"alice"}`)
+
+	neighbours := func(path string) (prev, next *Task) {
+		t.Helper()
+		w := doRequest(router, http.MethodGet, path, "")
+		expectStatus(t, w, http.StatusOK)
+		var resp struct {
+			Task     Task  `json:"task"`
+			Previous *Task `json:"previous"`
+			Next     *Task `json:"next"`
+		}
+		decodeBody(t, w, &resp)
+		return resp.Previous, resp.Next
+	}
+	id := func(task *Task) int {
+		if task == nil {
+			return 0
+		}
+		return task.ID
+	}
+
+	if prev, next := neighbours(taskLocation(middle.ID) + "/context"); id(prev) != first.ID || id(next) != last.ID {
+		t.Errorf("middle neighbours = %d/%d, want %d/%d", id(prev), id(next), first.ID, last.ID)
+	}
+	if prev, next := neighbours(taskLocation(first.ID) + "/context"); prev != nil || id(next) != middle.ID {
+		t.Errorf("first neighbours = %d/%d, want none/%d", id(prev), id(next), middle.ID)
+	}
+	if prev, next := neighbours(taskLocation(last.ID) + "/context"); id(prev) != middle.ID || next != nil {
+		t.Errorf("last neighbours = %d/%d, want %d/none", id(prev), id(next), middle.ID)
+	}
+	if prev, next := neighbours(taskLocation(first.ID) + "/context?assignee=alice"); prev != nil || id(next) != last.ID {
+		t.Errorf("filtered neighbours = %d/%d, want none/%d", id(prev), id(next), last.ID)
+	}
+
+	w := doRequest(router, http.MethodGet, "/task/999/context", "")
+	expectStatus(t, w, http.StatusNotFound)
+}
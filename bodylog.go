@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bodyRecorder passes a request body through to the handler, keeping the
+// first limit bytes for the log and counting the rest.
+type bodyRecorder struct {
+	io.ReadCloser
+	limit int
+	head  []byte
+	total int64
+}
+
+func (r *bodyRecorder) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if keep := min(n, r.limit-len(r.head)); keep > 0 {
+		r.head = append(r.head, p[:keep]...)
+	}
+	r.total += int64(n)
+	return n, err
+}
+
+// bodyLogger logs request bodies when LOG_BODIES is set. Bodies longer than
+// LOG_BODY_MAX_BYTES are cut with a "...[truncated N bytes]" marker, and
+// bodies that are not text, or are encoded, are summarized by length and
+// content type instead of dumped. The body is logged as the handler read
+// it, so nothing extra is buffered.
+func bodyLogger() gin.HandlerFunc {
+	enabled := envBool("LOG_BODIES", false)
+	limit := max(envInt("LOG_BODY_MAX_BYTES", 2048), 0)
+
+	return func(c *gin.Context) {
+		if !enabled || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		rec := &bodyRecorder{ReadCloser: c.Request.Body, limit: limit}
+		c.Request.Body = rec
+		c.Next()
+
+		total := max(rec.total, c.Request.ContentLength)
+		if total <= 0 {
+			return
+		}
+		log.Printf("request body %s %s [%s]: %s", c.Request.Method, c.Request.URL.Path,
+			c.Writer.Header().Get(requestIDHeader), describeBody(rec.head, total, c.ContentType(), c.GetHeader("Content-Encoding")))
+	}
+}
+
+// describeBody renders a logged body: the text itself, truncated when total
+// exceeds what was kept, or a summary when it is not safe to print.
+func describeBody(head []byte, total int64, contentType, encoding string) string {
+	text := head
+	if int64(len(text)) < total {
+		// Drop a rune split by the cut so it does not read as binary.
+		for i := 0; i < utf8.UTFMax-1 && len(text) > 0 && !utf8.Valid(text); i++ {
+			text = text[:len(text)-1]
+		}
+	}
+	if (encoding != "" && encoding != "identity") || !textualContentType(contentType) || !utf8.Valid(text) {
+		if contentType == "" {
+			contentType = "unknown content type"
+		}
+		return fmt.Sprintf("<%d bytes, %s>", total, contentType)
+	}
+
+	out := strconv.Quote(string(text))
+	if dropped := total - int64(len(text)); dropped > 0 {
+		out += fmt.Sprintf("...[truncated %d bytes]", dropped)
+	}
+	return out
+}
+
+// textualContentType reports whether a body of this type is readable text.
+// A missing type is treated as text and left to the UTF-8 check.
+func textualContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		mediaType == "application/json",
+		strings.HasSuffix(mediaType, "+json"),
+		mediaType == "application/xml",
+		strings.HasSuffix(mediaType, "+xml"),
+		mediaType == "application/x-www-form-urlencoded":
+		return true
+	}
+	return false
+}
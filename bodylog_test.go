@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDescribeBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		head        string
+		total       int64
+		contentType string
+		encoding    string
+		want        string
+	}{
+		{"whole", `{"a":1}`, 7, "application/json", "", `"{\"a\":1}"`},
+		{"truncated", "abcd", 10, "text/plain; charset=utf-8", "", `"abcd"...[truncated 6 bytes]`},
+		{"split rune", "ab\xc3", 5, "text/plain", "", `"ab"...[truncated 3 bytes]`},
+		{"binary type", "PNG", 1000, "image/png", "", "<1000 bytes, image/png>"},
+		{"invalid utf8", "\xff\xfe", 2, "", "", "<2 bytes, unknown content type>"},
+		{"encoded", "xx", 2, "application/json", "gzip", "<2 bytes, application/json>"},
+	}
+	for _, tt := range tests {
+		if got := describeBody([]byte(tt.head), tt.total, tt.contentType, tt.encoding); got != tt.want {
+			t.Errorf("%s: describeBody = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBodyLogger(t *testing.T) {
+	var logs bytes.Buffer
+	output := log.Writer()
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(output) })
+	router := newTestServer(t, "LOG_BODIES=true", "LOG_BODY_MAX_BYTES=16")
+
+	title := strings.Repeat("x", 40)
+	w := doRequest(router, http.MethodPost, "/task", `{"title":"`+title+`"}`)
+	expectStatus(t, w, http.StatusCreated)
+
+	// The handler still sees the whole body.
+	var task Task
+	decodeBody(t, w, &task)
+	if task.Title != title {
+		t.Errorf("title = %q, want the full title", task.Title)
+	}
+	if out := logs.String(); !strings.Contains(out, `request body POST /task`) || !strings.Contains(out, `...[truncated 36 bytes]`) {
+		t.Errorf("log = %q, want a truncated body entry", out)
+	}
+}
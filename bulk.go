@@ -0,0 +1,639 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type bulkUpdateRequest struct {
+	Filter taskFilter                 `json:"filter"`
+	Set    map[string]json.RawMessage `json:"set"`
+	All    bool                       `json:"all"`
+}
+
+// bulkUpdatableFields lists the columns bulkUpdateTasks may change.
+var bulkUpdatableFields = map[string]bool{
+	"status":   true,
+	"priority": true,
+}
+
+func bulkUpdateTasks(c *gin.Context) {
+	var req bulkUpdateRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if err := req.Filter.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if req.Filter.isEmpty() && !req.All {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "filter is required; set all=true to update every task",
+		})
+		return
+	}
+	if len(req.Set) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "set must name at least one field",
+		})
+		return
+	}
+
+	fields := make([]string, 0, len(req.Set))
+	for field := range req.Set {
+		if !bulkUpdatableFields[field] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("field %q cannot be bulk updated", field),
+			})
+			return
+		}
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	wf := currentWorkflow()
+	var (
+		assignments []string
+		args        []any
+		newStatus   string
+		newPriority *int
+	)
+	for _, field := range fields {
+		switch field {
+		case "status":
+			if err := json.Unmarshal(req.Set[field], &newStatus); err != nil || !wf.IsStatus(newStatus) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "invalid status",
+					"allowed": wf.Statuses,
+				})
+				return
+			}
+			assignments = append(assignments, "status = ?")
+			args = append(args, newStatus)
+		case "priority":
+			// A pointer tells null apart from 0, which would otherwise
+			// silently reset every matched task.
+			var priority *int
+			if err := json.Unmarshal(req.Set[field], &priority); err != nil || priority == nil || !validPriority(*priority) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fmt.Sprintf("priority must be between %d and %d", minPriority, maxPriority),
+				})
+				return
+			}
+			assignments = append(assignments, "priority = ?")
+			args = append(args, *priority)
+			newPriority = priority
+		}
+	}
+
+	tx, err := db.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	where, whereArgs := req.Filter.where()
+
+	rows, err := tx.Query("SELECT "+taskColumns+" FROM tasks"+where, whereArgs...)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+
+	var (
+		matched  []Task
+		blocked  []int
+		inferred []int
+	)
+	for rows.Next() {
+		var task Task
+		if err := scanTask(rows, &task); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan task",
+			})
+			return
+		}
+		if newStatus != "" && task.InferStatus && task.Status != newStatus {
+			inferred = append(inferred, task.ID)
+		} else if newStatus != "" && !wf.CanTransition(task.Status, newStatus) {
+			blocked = append(blocked, task.ID)
+		}
+		matched = append(matched, task)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+
+	if len(inferred) > 0 {
+		respondStatusInferred(c, inferred...)
+		return
+	}
+	if len(blocked) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    fmt.Sprintf("some tasks cannot move to %q", newStatus),
+			"task_ids": blocked,
+		})
+		return
+	}
+
+	// Dependencies finished by this same update do not block.
+	if wf.IsDone(newStatus) {
+		finishing := make(map[int]bool, len(matched))
+		for _, task := range matched {
+			finishing[task.ID] = true
+		}
+		var waiting []int
+		for _, task := range matched {
+			if wf.IsDone(task.Status) {
+				continue
+			}
+			open, err := openDependencyIDs(tx, task.ID)
+			if err != nil {
+				if respondTimedOut(c, err) {
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "failed to check dependencies",
+				})
+				return
+			}
+			for _, id := range open {
+				if !finishing[id] {
+					waiting = append(waiting, task.ID)
+					break
+				}
+			}
+		}
+		if len(waiting) > 0 {
+			respondBlocked(c, waiting)
+			return
+		}
+	}
+
+	now := time.Now()
+	assignments = append(assignments, "updated_at = ?")
+	args = append(args, formatDBTime(now))
+
+	result, err := tx.Exec("UPDATE tasks SET "+strings.Join(assignments, ", ")+where, append(args, whereArgs...)...)
+	if err == nil && newStatus != "" {
+		ids := make([]int, len(matched))
+		for i, task := range matched {
+			ids[i] = task.ID
+		}
+		err = syncCompletedAt(tx, now, ids...)
+		if err == nil {
+			err = refreshParentStatus(tx, requestActor(c), ids...)
+		}
+	}
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to update tasks",
+		})
+		return
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count updated tasks",
+		})
+		return
+	}
+
+	actor := requestActor(c)
+	for _, before := range matched {
+		after := before
+		if newStatus != "" {
+			after.Status = newStatus
+		}
+		if newPriority != nil {
+			after.Priority = *newPriority
+		}
+		changes := taskChanges(before, after)
+		if len(changes) == 0 {
+			continue
+		}
+		if err := recordAudit(tx, before.ID, "updated", actor, changes); err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to record audit entry",
+			})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to commit transaction",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"updated": updated,
+	})
+}
+
+type bulkDueRequest struct {
+	Filter taskFilter      `json:"filter"`
+	By     string          `json:"by"`
+	Shift  string          `json:"shift"`
+	On     json.RawMessage `json:"on"`
+}
+
+// parseDays reads a duration that may also be written in days or weeks,
+// such as "7d" or "2w", besides the usual Go forms like "36h".
+func parseDays(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil {
+				return 0, err
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+// bulkSetDueDates re-plans the tasks matching a filter. Exactly one of by,
+// shift and on chooses how: by sets the due date that far from now, shift
+// moves existing due dates (tasks without one are left alone) and on sets
+// a fixed date.
+func bulkSetDueDates(c *gin.Context) {
+	var req bulkDueRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if err := req.Filter.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if req.Filter.isEmpty() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "filter is required",
+		})
+		return
+	}
+
+	given := 0
+	for _, set := range []bool{req.By != "", req.Shift != "", len(req.On) > 0} {
+		if set {
+			given++
+		}
+	}
+	if given != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "exactly one of by, shift or on is required",
+		})
+		return
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	var due func(current *time.Time) *time.Time
+	switch {
+	case req.By != "":
+		d, err := parseDays(req.By)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "by must be a duration such as 7d or 36h",
+			})
+			return
+		}
+		at := now.Add(d)
+		due = func(*time.Time) *time.Time { return &at }
+	case req.Shift != "":
+		d, err := parseDays(req.Shift)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "shift must be a duration such as 7d or -36h",
+			})
+			return
+		}
+		due = func(current *time.Time) *time.Time {
+			if current == nil {
+				return nil
+			}
+			at := current.Add(d)
+			return &at
+		}
+	default:
+		at, err := parseDate(req.On)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": (&dateFieldError{Field: "on"}).Error(),
+			})
+			return
+		}
+		at = at.UTC()
+		due = func(*time.Time) *time.Time { return &at }
+	}
+
+	tx, err := db.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	where, args := req.Filter.where()
+	rows, err := tx.Query("SELECT "+taskColumns+" FROM tasks"+where, args...)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+	var matched []Task
+	for rows.Next() {
+		var task Task
+		if err := scanTask(rows, &task); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan task",
+			})
+			return
+		}
+		matched = append(matched, task)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+
+	actor := requestActor(c)
+	updated := 0
+	for _, before := range matched {
+		after := before
+		after.DueDate = due(before.DueDate)
+		changes := taskChanges(before, after)
+		if len(changes) == 0 {
+			continue
+		}
+
+		if _, err := tx.Exec("UPDATE tasks SET due_date = ?, updated_at = ? WHERE id = ?",
+			nullableDBTime(after.DueDate), formatDBTime(now), before.ID); err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to update tasks",
+			})
+			return
+		}
+		if err := recordAudit(tx, before.ID, "updated", actor, changes); err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to record audit entry",
+			})
+			return
+		}
+		updated++
+	}
+
+	if err := tx.Commit(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to commit transaction",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"updated": updated,
+	})
+}
+
+type bulkClearRequest struct {
+	Filter taskFilter `json:"filter"`
+	Fields []string   `json:"fields"`
+	All    bool       `json:"all"`
+}
+
+// bulkClearableFields lists the fields bulkClearTasks may empty. Required
+// columns such as title and status are deliberately missing.
+var bulkClearableFields = map[string]bool{
+	"due_date": true,
+	"assignee": true,
+	"tags":     true,
+}
+
+// bulkClearTasks empties the given fields on every matching task.
+func bulkClearTasks(c *gin.Context) {
+	var req bulkClearRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if err := req.Filter.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if req.Filter.isEmpty() && !req.All {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "filter is required; set all=true to clear every task",
+		})
+		return
+	}
+	if len(req.Fields) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "fields must name at least one field",
+		})
+		return
+	}
+	clearing := make(map[string]bool, len(req.Fields))
+	for _, field := range req.Fields {
+		if !bulkClearableFields[field] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("field %q cannot be cleared", field),
+			})
+			return
+		}
+		clearing[field] = true
+	}
+
+	tx, err := db.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	where, args := req.Filter.where()
+	rows, err := tx.Query("SELECT "+taskColumns+" FROM tasks"+where, args...)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+	var matched []Task
+	for rows.Next() {
+		var task Task
+		if err := scanTask(rows, &task); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan task",
+			})
+			return
+		}
+		matched = append(matched, task)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+	if err := attachTags(tx, matched); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tags",
+		})
+		return
+	}
+
+	actor := requestActor(c)
+	now := formatDBTime(time.Now())
+	cleared := 0
+	for _, before := range matched {
+		after := before
+		if clearing["due_date"] {
+			after.DueDate = nil
+		}
+		if clearing["assignee"] {
+			after.Assignee = ""
+		}
+		if clearing["tags"] {
+			after.Tags = []string{}
+		}
+		changes := taskChanges(before, after)
+		if len(changes) == 0 {
+			continue
+		}
+
+		if _, err := tx.Exec("UPDATE tasks SET due_date = ?, assignee = ?, updated_at = ? WHERE id = ?",
+			nullableDBTime(after.DueDate), after.Assignee, now, before.ID); err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to update tasks",
+			})
+			return
+		}
+		if _, ok := changes["tags"]; ok {
+			if err := setTaskTags(tx, before.ID, after.Tags); err != nil {
+				if respondTimedOut(c, err) {
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "failed to save tags",
+				})
+				return
+			}
+		}
+		if err := recordAudit(tx, before.ID, "updated", actor, changes); err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to record audit entry",
+			})
+			return
+		}
+		cleared++
+	}
+
+	if err := tx.Commit(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to commit transaction",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cleared": cleared,
+	})
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBulkUpdateTasks(t *testing.T) {
+	router := newTestServer(t)
+	high := createTestTask(t, router, `{"title":"a","priority":3}`)
+	low := createTestTask(t, router, `{"title":"b","priority":1}`)
+
+	w := doRequest(router, http.MethodPost, "/tasks/bulk-update",
+		`{"filter":{"priority_min":2},"set":{"status":"in_progress","priority":5}}`)
+	expectStatus(t, w, http.StatusOK)
+	var resp struct {
+		Updated int `json:"updated"`
+	}
+	decodeBody(t, w, &resp)
+	if resp.Updated != 1 {
+		t.Fatalf("updated = %d, want 1", resp.Updated)
+	}
+	if got := getTestTask(t, router, high.ID); got.Status != "in_progress" || got.Priority != 5 {
+		t.Errorf("matched task = %q/%d, want in_progress/5", got.Status, got.Priority)
+	}
+	if got := getTestTask(t, router, low.ID); got.Status != "todo" || got.Priority != 1 {
+		t.Errorf("unmatched task = %q/%d, want it unchanged", got.Status, got.Priority)
+	}
+}
+
+func TestBulkUpdateTasksRejectsBadRequests(t *testing.T) {
+	router := newTestServer(t)
+	task := createTestTask(t, router, `{"title":"a","priority":2}`)
+
+	for name, body := range map[string]string{
+		"no filter":      `{"set":{"status":"done"}}`,
+		"only deleted":   `{"filter":{"include_deleted":true},"set":{"priority":4}}`,
+		"empty set":      `{"filter":{"ids":[1]},"set":{}}`,
+		"unknown field":  `{"filter":{"ids":[1]},"set":{"title":"x"}}`,
+		"invalid status": `{"filter":{"ids":[1]},"set":{"status":"nope"}}`,
+		"null priority":  `{"filter":{"ids":[1]},"set":{"priority":null}}`,
+		"bad priority":   `{"filter":{"ids":[1]},"set":{"priority":99}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := doRequest(router, http.MethodPost, "/tasks/bulk-update", body)
+			expectStatus(t, w, http.StatusBadRequest)
+		})
+	}
+	if got := getTestTask(t, router, task.ID); got.Status != "todo" || got.Priority != 2 {
+		t.Fatalf("task = %q/%d, want it unchanged", got.Status, got.Priority)
+	}
+
+	w := doRequest(router, http.MethodPost, "/tasks/bulk-update", `{"all":true,"set":{"priority":4}}`)
+	expectStatus(t, w, http.StatusOK)
+	if got := getTestTask(t, router, task.ID); got.Priority != 4 {
+		t.Fatalf("priority = %d after all=true, want 4", got.Priority)
+	}
+}
+
+func TestParseDays(t *testing.T) {
+	for raw, want := range map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"-1d": -24 * time.Hour,
+		"36h": 36 * time.Hour,
+	} {
+		if got, err := parseDays(raw); err != nil || got != want {
+			t.Errorf("parseDays(%q) = %v, %v; want %v", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"d", "1.5w", "soon"} {
+		if _, err := parseDays(raw); err == nil {
+			t.Errorf("parseDays(%q) succeeded", raw)
+		}
+	}
+}
+
+func TestBulkSetDueDates(t *testing.T) {
+	router := newTestServer(t)
+	dated := createTestTask(t, router, `{"title":"dated","assignee":"ana","due_date":"2026-11-01T00:00:00Z"}`)
+	undated := createTestTask(t, router, `{"title":"undated","assignee":"ana"}`)
+	other := createTestTask(t, router, `{"title":"other","assignee":"bo"}`)
+	bulkDue := func(body string) int {
+		t.Helper()
+		w := doRequest(router, http.MethodPost, "/tasks/bulk-due", body)
+		expectStatus(t, w, http.StatusOK)
+		var resp struct {
+			Updated int `json:"updated"`
+		}
+		decodeBody(t, w, &resp)
+		return resp.Updated
+	}
+
+	// shift leaves tasks without a due date alone.
+	if n := bulkDue(`{"filter":{"assignee":"ana"},"shift":"1w"}`); n != 1 {
+		t.Errorf("shift updated %d tasks, want 1", n)
+	}
+	if got := getTestTask(t, router, dated.ID); !got.DueDate.Equal(time.Date(2026, 11, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("shifted due_date = %v, want a week later", got.DueDate)
+	}
+
+	before := time.Now()
+	if n := bulkDue(`{"filter":{"assignee":"ana"},"by":"7d"}`); n != 2 {
+		t.Errorf("by updated %d tasks, want 2", n)
+	}
+	got := getTestTask(t, router, undated.ID)
+	if got.DueDate == nil || got.DueDate.Sub(before) < 7*24*time.Hour-time.Second || got.DueDate.Sub(before) > 7*24*time.Hour+2*time.Second {
+		t.Errorf("due_date = %v, want a week from now", got.DueDate)
+	}
+	if actions := auditActions(t, router, undated.ID); len(actions) != 2 || !slices.Contains(actions, "updated") {
+		t.Errorf("audit = %v, want an updated entry", actions)
+	}
+
+	if n := bulkDue(`{"filter":{"ids":[` + strconv.Itoa(other.ID) + `]},"on":"2027-01-01T00:00:00Z"}`); n != 1 {
+		t.Errorf("on updated %d tasks, want 1", n)
+	}
+	if got := getTestTask(t, router, other.ID); !got.DueDate.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("due_date = %v, want 2027-01-01", got.DueDate)
+	}
+
+	for _, body := range []string{
+		`{"by":"7d"}`,
+		`{"filter":{"assignee":"ana"}}`,
+		`{"filter":{"assignee":"ana"},"by":"7d","shift":"1d"}`,
+		`{"filter":{"assignee":"ana"},"by":"a week"}`,
+		`{"filter":{"assignee":"ana"},"on":"someday"}`,
+	} {
+		w := doRequest(router, http.MethodPost, "/tasks/bulk-due", body)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
+
+func TestBulkClearTasks(t *testing.T) {
+	router := newTestServer(t)
+	due := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	mine := createTestTask(t, router, `{"title":"a","assignee":"sam","due_date":"`+due+`","tags":["x"]}`)
+	undated := createTestTask(t, router, `{"title":"b","assignee":"sam"}`)
+	theirs := createTestTask(t, router, `{"title":"c","assignee":"kim","due_date":"`+due+`"}`)
+
+	w := doRequest(router, http.MethodPost, "/tasks/bulk-clear", `{"filter":{"assignee":"sam"},"fields":["due_date"]}`)
+	expectStatus(t, w, http.StatusOK)
+	var resp struct {
+		Cleared int `json:"cleared"`
+	}
+	decodeBody(t, w, &resp)
+	if resp.Cleared != 1 {
+		t.Errorf("cleared = %d, want 1: the undated task had nothing to clear", resp.Cleared)
+	}
+	if got := getTestTask(t, router, mine.ID); got.DueDate != nil || got.Assignee != "sam" || len(got.Tags) != 1 {
+		t.Errorf("cleared task = %+v, want only its due date emptied", got)
+	}
+	if got := getTestTask(t, router, theirs.ID); got.DueDate == nil {
+		t.Error("task outside the filter lost its due date")
+	}
+	if actions := auditActions(t, router, mine.ID); actions[0] != "updated" {
+		t.Errorf("audit = %v, want an update recorded", actions)
+	}
+	if actions := auditActions(t, router, undated.ID); slices.Contains(actions, "updated") {
+		t.Errorf("unchanged task audit = %v, want no update", actions)
+	}
+
+	w = doRequest(router, http.MethodPost, "/tasks/bulk-clear", `{"all":true,"fields":["assignee","tags"]}`)
+	expectStatus(t, w, http.StatusOK)
+	if got := getTestTask(t, router, mine.ID); got.Assignee != "" || len(got.Tags) != 0 {
+		t.Errorf("task after clearing all = %+v, want no assignee or tags", got)
+	}
+}
+
+func TestBulkClearTasksRejectsBadRequests(t *testing.T) {
+	router := newTestServer(t)
+	task := createTestTask(t, router, `{"title":"a","assignee":"sam"}`)
+
+	for name, body := range map[string]string{
+		"no filter":      `{"fields":["assignee"]}`,
+		"no fields":      `{"filter":{"assignee":"sam"}}`,
+		"required field": `{"filter":{"assignee":"sam"},"fields":["title"]}`,
+		"unknown field":  `{"filter":{"assignee":"sam"},"fields":["assignee","colour"]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := doRequest(router, http.MethodPost, "/tasks/bulk-clear", body)
+			expectStatus(t, w, http.StatusBadRequest)
+		})
+	}
+	if got := getTestTask(t, router, task.ID); got.Assignee != "sam" {
+		t.Fatalf("assignee = %q, want it unchanged", got.Assignee)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeCacheControl is the Cache-Control value for routes matching Route,
+// which is a gin pattern or a prefix ending in "*".
+type routeCacheControl struct {
+	Route string
+	Value string
+}
+
+// cacheDirectives lists the Cache-Control response directives accepted in
+// configuration, and whether each takes a number of seconds.
+var cacheDirectives = map[string]bool{
+	"max-age":                true,
+	"s-maxage":               true,
+	"stale-while-revalidate": true,
+	"stale-if-error":         true,
+	"no-store":               false,
+	"no-cache":               false,
+	"must-revalidate":        false,
+	"proxy-revalidate":       false,
+	"public":                 false,
+	"private":                false,
+	"immutable":              false,
+	"no-transform":           false,
+}
+
+// parseCacheControl validates a Cache-Control value such as
+// "max-age=30, must-revalidate" and returns it normalized.
+func parseCacheControl(s string) (string, error) {
+	var directives []string
+	for _, part := range strings.Split(s, ",") {
+		name, arg, hasArg := strings.Cut(strings.ToLower(strings.TrimSpace(part)), "=")
+		takesSeconds, ok := cacheDirectives[name]
+		if !ok {
+			return "", fmt.Errorf("unknown cache directive %q", strings.TrimSpace(part))
+		}
+		if takesSeconds != hasArg {
+			return "", fmt.Errorf("cache directive %q must be written as %s", strings.TrimSpace(part), directiveUsage(name, takesSeconds))
+		}
+		if hasArg {
+			if n, err := strconv.Atoi(arg); err != nil || n < 0 {
+				return "", fmt.Errorf("cache directive %q must be written as %s", strings.TrimSpace(part), directiveUsage(name, true))
+			}
+		}
+		directives = append(directives, strings.TrimSpace(strings.ToLower(part)))
+	}
+	return strings.Join(directives, ", "), nil
+}
+
+func directiveUsage(name string, takesSeconds bool) string {
+	if takesSeconds {
+		return name + "=<seconds>"
+	}
+	return name
+}
+
+// loadCacheControl reads CACHE_CONTROL, a semicolon-separated list of
+// route=value pairs such as
+// "/tasks/stats=max-age=30;/task/:id=no-cache;/ping=no-store". Routes not
+// listed get no Cache-Control header from this middleware.
+func loadCacheControl() ([]routeCacheControl, error) {
+	raw := os.Getenv("CACHE_CONTROL")
+	if raw == "" {
+		return nil, nil
+	}
+
+	var routes []routeCacheControl
+	for _, pair := range strings.Split(raw, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		route, value, ok := strings.Cut(pair, "=")
+		route = strings.TrimSpace(route)
+		if !ok || route == "" {
+			return nil, fmt.Errorf("CACHE_CONTROL: invalid entry %q, expected route=directives", pair)
+		}
+		value, err := parseCacheControl(value)
+		if err != nil {
+			return nil, fmt.Errorf("CACHE_CONTROL: %s: %w", route, err)
+		}
+		routes = append(routes, routeCacheControl{Route: route, Value: value})
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		return moreSpecificRoute(routes[i].Route, routes[j].Route)
+	})
+	return routes, nil
+}
+
+// cacheControlMiddleware sets the configured Cache-Control header on GET and
+// HEAD responses by matched route. Handlers that set their own, such as the
+// event stream, still override it.
+func cacheControlMiddleware(routes []routeCacheControl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			for _, r := range routes {
+				if routeMatches(r.Route, c.FullPath()) {
+					c.Header("Cache-Control", r.Value)
+					break
+				}
+			}
+		}
+		c.Next()
+	}
+}
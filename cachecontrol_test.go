@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCacheControlPerRoute(t *testing.T) {
+	router := newTestServer(t, "CACHE_CONTROL=/tasks/workload=max-age=30;/task/:id=No-Cache, must-revalidate;/tasks*=max-age=5;/ping=no-store")
+	task := createTestTask(t, router, `{"title":"cached"}`)
+
+	for _, tt := range []struct {
+		method, path, want string
+	}{
+		{http.MethodGet, "/tasks/workload", "max-age=30"},
+		{http.MethodGet, taskLocation(task.ID), "no-cache, must-revalidate"},
+		{http.MethodHead, taskLocation(task.ID), "no-cache, must-revalidate"},
+		{http.MethodGet, "/tasks", "max-age=5"},
+		{http.MethodGet, "/ping", "no-store"},
+		{http.MethodPatch, taskLocation(task.ID), ""},
+	} {
+		body := ""
+		if tt.method == http.MethodPatch {
+			body = `{"priority":2}`
+		}
+		w := doRequest(router, tt.method, tt.path, body)
+		if got := w.Header().Get("Cache-Control"); got != tt.want {
+			t.Errorf("%s %s Cache-Control = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLoadCacheControlRejectsBadDirectives(t *testing.T) {
+	for _, raw := range []string{"/ping=forever", "/ping=max-age", "/ping=no-store=1", "/tasks/workload=max-age=-1", "no-store"} {
+		t.Setenv("CACHE_CONTROL", raw)
+		if _, err := loadCacheControl(); err == nil {
+			t.Errorf("loadCacheControl accepted %q", raw)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCloneTasks caps how many tasks one clone request may copy.
+const maxCloneTasks = 500
+
+type cloneRequest struct {
+	Filter         taskFilter `json:"filter"`
+	All            bool       `json:"all"`
+	ResetStatus    bool       `json:"reset_status"`
+	ShiftDue       string     `json:"shift_due"`
+	ClearAssignees bool       `json:"clear_assignees"`
+}
+
+// cloneTasks copies every task matching a filter as a new task owned by the
+// caller, e.g. to start a sprint from the previous one. Subtasks whose
+// parent is also copied are attached to the parent's copy; those whose
+// parent is not copied keep the original parent. Options reset statuses to
+// the workflow's initial status, move due dates by a duration and clear
+// assignees.
+func cloneTasks(c *gin.Context) {
+	var req cloneRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if err := req.Filter.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if req.Filter.isEmpty() && !req.All {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "filter is required; set all=true to clone every task",
+		})
+		return
+	}
+	var shift time.Duration
+	if req.ShiftDue != "" {
+		d, err := parseDays(req.ShiftDue)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "shift_due must be a duration such as 2w or 36h",
+			})
+			return
+		}
+		shift = d
+	}
+	req.Filter.IncludeDeleted = false
+
+	tx, err := db.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	where, args := req.Filter.where()
+	rows, err := tx.Query("SELECT "+taskColumns+" FROM tasks"+where+" ORDER BY id LIMIT ?", append(args, maxCloneTasks+1)...)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+	var originals []Task
+	for rows.Next() {
+		var task Task
+		if err := scanTask(rows, &task); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan task",
+			})
+			return
+		}
+		originals = append(originals, task)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+	if len(originals) > maxCloneTasks {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "too many tasks to clone; narrow the filter",
+			"limit": maxCloneTasks,
+		})
+		return
+	}
+	if err := attachTags(tx, originals); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tags",
+		})
+		return
+	}
+
+	selected := make(map[int]bool, len(originals))
+	for _, t := range originals {
+		selected[t.ID] = true
+	}
+
+	wf := currentWorkflow()
+	actor := requestActor(c)
+	clones := make(map[int]int, len(originals))
+	// Copy parents before their subtasks so the parent's new id is known.
+	for pending := originals; len(pending) > 0; {
+		var waiting []Task
+		for _, orig := range pending {
+			parentInSet := orig.ParentID != nil && selected[*orig.ParentID]
+			if parentInSet {
+				if _, copied := clones[*orig.ParentID]; !copied {
+					waiting = append(waiting, orig)
+					continue
+				}
+			}
+
+			clone := Task{
+				Title:    orig.Title,
+				Status:   orig.Status,
+				Priority: orig.Priority,
+				Position: orig.Position,
+				Assignee: orig.Assignee,
+				Owner:    actor,
+				DueDate:  orig.DueDate,
+				ParentID: orig.ParentID,
+				Tags:     orig.Tags,
+			}
+			if parentInSet {
+				parent := clones[*orig.ParentID]
+				clone.ParentID = &parent
+			}
+			if req.ResetStatus {
+				clone.Status = wf.Initial
+			}
+			if req.ClearAssignees {
+				clone.Assignee = ""
+			}
+			if clone.DueDate != nil && shift != 0 {
+				due := clone.DueDate.Add(shift)
+				clone.DueDate = &due
+			}
+
+			if err := insertTask(tx, &clone, actor); err != nil {
+				if respondTimedOut(c, err) {
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "failed to clone task",
+				})
+				return
+			}
+			clones[orig.ID] = clone.ID
+		}
+		if len(waiting) == len(pending) {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "subtask relationships could not be resolved",
+			})
+			return
+		}
+		pending = waiting
+	}
+
+	if ownerTaskQuota.Limit > 0 {
+		owned, err := ownedTaskCount(tx, actor)
+		if err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to count owned tasks",
+			})
+			return
+		}
+		if !ownerTaskQuota.check(c, owned, http.StatusForbidden, "task quota exceeded") {
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to commit transaction",
+		})
+		return
+	}
+
+	ids := make([]int, 0, len(originals))
+	idMap := make(map[string]int, len(originals))
+	for _, orig := range originals {
+		ids = append(ids, clones[orig.ID])
+		idMap[strconv.Itoa(orig.ID)] = clones[orig.ID]
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"cloned":   len(ids),
+		"task_ids": ids,
+		"id_map":   idMap,
+	})
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCloneTasks(t *testing.T) {
+	router := newTestServer(t)
+	parent := createTestTask(t, router, `{"title":"epic","status":"in_progress","assignee":"sprint","due_date":"2026-11-02T00:00:00Z"}`)
+	child := createTestTask(t, router, fmt.Sprintf(`{"title":"story","status":"done","assignee":"sprint","parent_id":%d}`, parent.ID))
+	outside := createTestTask(t, router, `{"title":"outside","assignee":"other"}`)
+	loose := createTestTask(t, router, fmt.Sprintf(`{"title":"loose","assignee":"sprint","parent_id":%d}`, outside.ID))
+
+	w := doRequest(router, http.MethodPost, "/tasks/clone",
+		`{"filter":{"assignee":"sprint"},"reset_status":true,"shift_due":"2w","clear_assignees":true}`)
+	expectStatus(t, w, http.StatusCreated)
+	var resp struct {
+		Cloned int            `json:"cloned"`
+		IDMap  map[string]int `json:"id_map"`
+	}
+	decodeBody(t, w, &resp)
+	if resp.Cloned != 3 {
+		t.Fatalf("cloned = %d, want 3", resp.Cloned)
+	}
+	copyOf := func(task Task) Task {
+		t.Helper()
+		return getTestTask(t, router, resp.IDMap[strconv.Itoa(task.ID)])
+	}
+
+	epic := copyOf(parent)
+	want := time.Date(2026, 11, 16, 0, 0, 0, 0, time.UTC)
+	if epic.Status != "todo" || epic.Assignee != "" || epic.DueDate == nil || !epic.DueDate.Equal(want) {
+		t.Errorf("epic copy = %+v, want reset status, no assignee and due %v", epic, want)
+	}
+	if story := copyOf(child); story.ParentID == nil || *story.ParentID != epic.ID {
+		t.Errorf("story copy parent = %v, want the epic copy %d", story.ParentID, epic.ID)
+	}
+	if got := copyOf(loose); got.ParentID == nil || *got.ParentID != outside.ID {
+		t.Errorf("loose copy parent = %v, want the original parent %d", got.ParentID, outside.ID)
+	}
+	if got := getTestTask(t, router, parent.ID); got.Status != "in_progress" || got.Assignee != "sprint" {
+		t.Errorf("original = %+v, want it unchanged", got)
+	}
+}
+
+func TestCloneTasksRejectsBadRequests(t *testing.T) {
+	router := newTestServer(t)
+	createTestTask(t, router, `{"title":"a"}`)
+	for _, body := range []string{
+		`{"reset_status":true}`,
+		`{"filter":{"status":"todo"},"shift_due":"fortnight"}`,
+		`{"filter":{"priority_min":4,"priority_max":1}}`,
+	} {
+		w := doRequest(router, http.MethodPost, "/tasks/clone", body)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
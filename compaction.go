@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditRetentionConfig controls audit log compaction. Entries older than
+// MaxAge are deleted, except the KeepPerTask most recent entries of each
+// task. Compaction is disabled when MaxAge is zero.
+type auditRetentionConfig struct {
+	MaxAge      time.Duration
+	KeepPerTask int
+	Interval    time.Duration
+}
+
+// auditRetention is the configuration loaded at startup, also used by the
+// manual compaction endpoint.
+var auditRetention auditRetentionConfig
+
+func loadAuditRetentionConfig() (auditRetentionConfig, error) {
+	cfg := auditRetentionConfig{
+		MaxAge:      envDuration("AUDIT_RETENTION", 0),
+		KeepPerTask: envInt("AUDIT_KEEP_PER_TASK", 10),
+		Interval:    envDuration("AUDIT_COMPACT_INTERVAL", time.Hour),
+	}
+	if cfg.MaxAge < 0 {
+		return cfg, errors.New("AUDIT_RETENTION must not be negative")
+	}
+	if cfg.KeepPerTask < 0 {
+		return cfg, errors.New("AUDIT_KEEP_PER_TASK must not be negative")
+	}
+	return cfg, nil
+}
+
+func startAuditCompactionJob(cfg auditRetentionConfig) {
+	if cfg.MaxAge <= 0 || cfg.Interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			n, err := compactAuditLog(cfg.MaxAge, cfg.KeepPerTask, time.Now())
+			if err != nil {
+				log.Printf("audit compaction failed: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("audit compaction removed %d entries", n)
+			}
+		}
+	}()
+}
+
+// compactAuditLog deletes audit entries older than maxAge beyond the keep
+// most recent of each task, in a single statement so concurrent writes are
+// never caught half way. Creation entries are always kept because the ETA
+// estimate measures from them.
+func compactAuditLog(maxAge time.Duration, keep int, now time.Time) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM task_audit
+		WHERE created_at < ? AND action <> 'created' AND id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY task_id ORDER BY id DESC) AS rn FROM task_audit
+			) WHERE rn > ?
+		)`, formatDBTime(now.Add(-maxAge)), keep)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return n, tx.Commit()
+}
+
+// compactAudit runs compaction on demand. ?older_than= overrides the
+// configured AUDIT_RETENTION, which otherwise must be set.
+func compactAudit(c *gin.Context) {
+	maxAge := auditRetention.MaxAge
+	if raw := c.Query("older_than"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "older_than must be a positive duration",
+			})
+			return
+		}
+		maxAge = d
+	}
+	if maxAge <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "no retention configured; set AUDIT_RETENTION or pass older_than",
+		})
+		return
+	}
+
+	n, err := compactAuditLog(maxAge, auditRetention.KeepPerTask, time.Now())
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to compact audit log",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted":       n,
+		"older_than":    maxAge.String(),
+		"keep_per_task": auditRetention.KeepPerTask,
+	})
+}
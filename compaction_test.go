@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestCompactAudit(t *testing.T) {
+	router := newTestServer(t, "ADMIN_TOKEN=secret", "AUDIT_KEEP_PER_TASK=2")
+	cfg, err := loadAuditRetentionConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditRetention = cfg
+	t.Cleanup(func() { auditRetention = auditRetentionConfig{} })
+
+	old := createTestTask(t, router, `{"title":"old"}`)
+	for p := 1; p <= 4; p++ {
+		doRequest(router, http.MethodPatch, taskLocation(old.ID), fmt.Sprintf(`{"priority":%d}`, p))
+	}
+	if _, err := db.Exec("UPDATE task_audit SET created_at = ?", "2000-01-01T00:00:00Z"); err != nil {
+		t.Fatal(err)
+	}
+	recent := createTestTask(t, router, `{"title":"recent"}`)
+	for p := 1; p <= 4; p++ {
+		doRequest(router, http.MethodPatch, taskLocation(recent.ID), fmt.Sprintf(`{"priority":%d}`, p))
+	}
+	auth := "Authorization: Bearer secret"
+
+	w := doRequest(router, http.MethodPost, "/admin/audit/compact", "", auth)
+	expectStatus(t, w, http.StatusBadRequest)
+
+	w = doRequest(router, http.MethodPost, "/admin/audit/compact?older_than=24h", "", auth)
+	expectStatus(t, w, http.StatusOK)
+	var resp struct {
+		Deleted int `json:"deleted"`
+	}
+	decodeBody(t, w, &resp)
+	if resp.Deleted != 2 {
+		t.Errorf("deleted = %d, want the two oldest updates", resp.Deleted)
+	}
+	if got := auditActions(t, router, old.ID); len(got) != 3 || !slices.Contains(got, "created") {
+		t.Errorf("old task audit = %v, want created plus the two newest entries", got)
+	}
+	if got := auditActions(t, router, recent.ID); len(got) != 5 {
+		t.Errorf("recent task audit = %v, want all five entries", got)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// syncCompletedAt sets completed_at on the given tasks that are now in a
+// done status and have none yet, and clears it on tasks that have left one.
+// Call it after any statement that changes status.
+func syncCompletedAt(ex queryExecer, now time.Time, ids ...int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	wf := currentWorkflow()
+	args := make([]any, 0, len(wf.Done)+1+len(ids))
+	for _, s := range wf.Done {
+		args = append(args, s)
+	}
+	args = append(args, formatDBTime(now))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	_, err := ex.Exec(`UPDATE tasks SET completed_at = CASE WHEN status IN (`+placeholders(len(wf.Done))+`)
+		THEN COALESCE(completed_at, ?) ELSE NULL END
+		WHERE id IN (`+placeholders(len(ids))+`)`, args...)
+	return err
+}
+
+// backfillCompletedAt gives done tasks stored before completed_at existed
+// their last update time as an approximate completion time.
+func backfillCompletedAt() error {
+	wf := currentWorkflow()
+	if len(wf.Done) == 0 {
+		return nil
+	}
+
+	args := make([]any, len(wf.Done))
+	for i, s := range wf.Done {
+		args[i] = s
+	}
+	_, err := db.Exec(`UPDATE tasks SET completed_at = COALESCE(updated_at, created_at)
+		WHERE completed_at IS NULL AND status IN (`+placeholders(len(args))+`)`, args...)
+	return err
+}
+
+// getCompletedTasks feeds a "what got done" view: tasks completed after
+// since, most recent first, narrowed by the usual query filters and
+// paginated.
+func getCompletedTasks(c *gin.Context) {
+	raw := c.Query("since")
+	if raw == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "since is required",
+		})
+		return
+	}
+	since, err := parseDateString(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": (&dateFieldError{Field: "since"}).Error(),
+		})
+		return
+	}
+
+	filter, err := taskFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	page, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	where, args := filter.where()
+	if where == "" {
+		where = " WHERE completed_at > ?"
+	} else {
+		where += " AND completed_at > ?"
+	}
+	args = append(args, formatDBTime(since))
+
+	var total int
+	if err := db.QueryRowContext(c.Request.Context(), "SELECT COUNT(*) FROM tasks"+where, args...).Scan(&total); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count tasks",
+		})
+		return
+	}
+
+	rows, err := db.QueryContext(c.Request.Context(), "SELECT "+taskColumns+" FROM tasks"+where+" ORDER BY completed_at DESC, id DESC LIMIT ? OFFSET ?",
+		append(args, page.Limit, page.Offset)...)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+	defer rows.Close()
+
+	tasks := []Task{}
+	for rows.Next() {
+		var task Task
+		if err := scanTask(rows, &task); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan task",
+			})
+			return
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+
+	if err := attachTags(requestDB(c), tasks); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tags",
+		})
+		return
+	}
+
+	setTotalCount(c, total)
+	respondList(c, tasks, pageMeta(total, page))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetCompletedTasks(t *testing.T) {
+	router := newTestServer(t)
+	var done []Task
+	for _, title := range []string{"old", "first", "second"} {
+		task := createTestTask(t, router, `{"title":"`+title+`"}`)
+		doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"status":"done"}`)
+		done = append(done, task)
+	}
+	reopened := createTestTask(t, router, `{"title":"reopened","status":"done"}`)
+	doRequest(router, http.MethodPatch, taskLocation(reopened.ID), `{"status":"todo"}`)
+	if got := getTestTask(t, router, reopened.ID); got.CompletedAt != nil {
+		t.Errorf("reopened task completed_at = %v, want it cleared", got.CompletedAt)
+	}
+	for i, at := range []string{"2000-01-01T00:00:00Z", "2026-01-01T00:00:00Z", "2026-02-01T00:00:00Z"} {
+		if _, err := db.Exec("UPDATE tasks SET completed_at = ? WHERE id = ?", at, done[i].ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := doRequest(router, http.MethodGet, "/tasks/completed?since=2020-01-01T00:00:00Z", "")
+	expectStatus(t, w, http.StatusOK)
+	var tasks []Task
+	decodeBody(t, w, &tasks)
+	if len(tasks) != 2 || tasks[0].ID != done[2].ID || tasks[1].ID != done[1].ID {
+		t.Fatalf("completed = %+v, want second then first", tasks)
+	}
+
+	w = doRequest(router, http.MethodGet, "/tasks/completed?since=2020-01-01T00:00:00Z&limit=1&offset=1", "")
+	tasks = nil
+	decodeBody(t, w, &tasks)
+	if len(tasks) != 1 || tasks[0].ID != done[1].ID {
+		t.Errorf("second page = %+v, want the first completion", tasks)
+	}
+
+	for _, query := range []string{"", "?since=2020-01-01", "?since=2020-01-01T00:00:00Z&priority_max=9"} {
+		w := doRequest(router, http.MethodGet, "/tasks/completed"+query, "")
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%q: status = %d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestGetCompletedTasksLenientSince(t *testing.T) {
+	router := newTestServer(t, "DATE_PARSING=lenient")
+	task := createTestTask(t, router, `{"title":"done","status":"done"}`)
+
+	w := doRequest(router, http.MethodGet, "/tasks/completed?since=2020-01-01", "")
+	expectStatus(t, w, http.StatusOK)
+	var tasks []Task
+	decodeBody(t, w, &tasks)
+	if len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Errorf("completed = %+v, want the done task", tasks)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// envInt returns the integer value of key, or def when it is unset or invalid.
+func envInt(key string, def int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Printf("ignoring invalid %s=%q: %v", key, raw, err)
+		return def
+	}
+	return v
+}
+
+// envDuration returns the duration value of key, or def when it is unset or invalid.
+func envDuration(key string, def time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+
+	v, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("ignoring invalid %s=%q: %v", key, raw, err)
+		return def
+	}
+	return v
+}
+
+// envBool returns the boolean value of key, or def when it is unset or invalid.
+func envBool(key string, def bool) bool {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Printf("ignoring invalid %s=%q: %v", key, raw, err)
+		return def
+	}
+	return v
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	dateParsingStrict  = "strict"
+	dateParsingLenient = "lenient"
+)
+
+// dateParsingMode is set from DATE_PARSING at startup.
+var dateParsingMode = dateParsingStrict
+
+// dateFields lists the task JSON fields holding a date.
+var dateFields = []string{"due_date"}
+
+// lenientDateLayouts are tried in order in lenient mode. Layouts without a
+// zone are read in the server timezone.
+var lenientDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+func loadDateParsing() (string, error) {
+	mode := os.Getenv("DATE_PARSING")
+	switch mode {
+	case "":
+		return dateParsingStrict, nil
+	case dateParsingStrict, dateParsingLenient:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("DATE_PARSING must be %q or %q", dateParsingStrict, dateParsingLenient)
+	}
+}
+
+func initDateParsing() error {
+	mode, err := loadDateParsing()
+	if err != nil {
+		return err
+	}
+	dateParsingMode = mode
+	return nil
+}
+
+// dateFieldError reports a date value the configured parser rejected.
+type dateFieldError struct {
+	Field string
+}
+
+func (e *dateFieldError) Error() string {
+	if dateParsingMode == dateParsingLenient {
+		return fmt.Sprintf("%s must be an RFC 3339 timestamp, a YYYY-MM-DD date or unix seconds", e.Field)
+	}
+	return fmt.Sprintf("%s must be an RFC 3339 timestamp such as 2006-01-02T15:04:05Z", e.Field)
+}
+
+// parseDate reads one date in the configured mode. Strict mode accepts only
+// RFC 3339; lenient mode also accepts the layouts in lenientDateLayouts and
+// unix seconds, as a number or a numeric string.
+func parseDate(raw json.RawMessage) (time.Time, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		if dateParsingMode == dateParsingLenient {
+			var secs int64
+			if err := json.Unmarshal(raw, &secs); err == nil {
+				return time.Unix(secs, 0).UTC(), nil
+			}
+		}
+		return time.Time{}, errors.New("not a date")
+	}
+	return parseDateString(s)
+}
+
+// parseDateString reads a date given as text, such as a query parameter, in
+// the configured mode.
+func parseDateString(s string) (time.Time, error) {
+	if dateParsingMode != dateParsingLenient {
+		return time.Parse(time.RFC3339Nano, s)
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	for _, layout := range lenientDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, serverLocation()); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, errors.New("not a date")
+}
+
+// normalizeDateFields rewrites the date fields of a JSON object body to RFC
+// 3339 UTC so they decode into time.Time. Bodies without date fields are
+// returned unchanged.
+func normalizeDateFields(body []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+
+	changed := false
+	for _, field := range dateFields {
+		raw, ok := fields[field]
+		if !ok || bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			continue
+		}
+		t, err := parseDate(raw)
+		if err != nil {
+			return nil, &dateFieldError{Field: field}
+		}
+		fields[field], _ = json.Marshal(t.UTC().Format(time.RFC3339Nano))
+		changed = true
+	}
+	if !changed {
+		return body, nil
+	}
+	return json.Marshal(fields)
+}
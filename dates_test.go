@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDateParsingStrict(t *testing.T) {
+	router := newTestServer(t, "DATE_PARSING=strict")
+
+	task := createTestTask(t, router, `{"title":"a","due_date":"2026-11-01T09:30:00+02:00"}`)
+	if want := time.Date(2026, 11, 1, 7, 30, 0, 0, time.UTC); task.DueDate == nil || !task.DueDate.Equal(want) {
+		t.Errorf("due_date = %v, want %v", task.DueDate, want)
+	}
+
+	for _, due := range []string{`"2026-11-01"`, `1798761600`, `"next week"`} {
+		w := doRequest(router, http.MethodPost, "/task", `{"title":"b","due_date":`+due+`}`)
+		expectStatus(t, w, http.StatusBadRequest)
+		if !strings.Contains(w.Body.String(), "due_date must be an RFC 3339 timestamp") {
+			t.Errorf("due_date %s: body = %s, want the strict format error", due, w.Body.String())
+		}
+	}
+}
+
+func TestDateParsingLenient(t *testing.T) {
+	router := newTestServer(t, "DATE_PARSING=lenient")
+	midnight := time.Date(2026, 11, 1, 0, 0, 0, 0, serverLocation())
+
+	for due, want := range map[string]time.Time{
+		`"2026-11-01T09:30:00Z"`: time.Date(2026, 11, 1, 9, 30, 0, 0, time.UTC),
+		`"2026-11-01"`:           midnight,
+		`"2026-11-01 08:00:00"`:  midnight.Add(8 * time.Hour),
+		`1798761600`:             time.Unix(1798761600, 0),
+		`"1798761600"`:           time.Unix(1798761600, 0),
+	} {
+		w := doRequest(router, http.MethodPost, "/task", `{"title":"a","due_date":`+due+`}`)
+		expectStatus(t, w, http.StatusCreated)
+		var task Task
+		decodeBody(t, w, &task)
+		if task.DueDate == nil || !task.DueDate.Equal(want) || task.DueDate.Location() != time.UTC {
+			t.Errorf("due_date %s = %v, want %v in UTC", due, task.DueDate, want)
+		}
+	}
+
+	w := doRequest(router, http.MethodPost, "/task", `{"title":"a","due_date":"soon"}`)
+	expectStatus(t, w, http.StatusBadRequest)
+	if !strings.Contains(w.Body.String(), "a YYYY-MM-DD date or unix seconds") {
+		t.Errorf("body = %s, want the lenient format error", w.Body.String())
+	}
+}
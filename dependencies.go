@@ -0,0 +1,342 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type addDependencyRequest struct {
+	DependsOn taskRef `json:"depends_on"`
+}
+
+// dependsOnTransitively reports whether from reaches to by following
+// depends-on edges.
+func dependsOnTransitively(q queryer, from, to int) (bool, error) {
+	seen := map[int]bool{from: true}
+	queue := []int{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		rows, err := q.Query("SELECT depends_on_id FROM task_dependencies WHERE task_id = ?", id)
+		if err != nil {
+			return false, err
+		}
+		for rows.Next() {
+			var next int
+			if err := rows.Scan(&next); err != nil {
+				rows.Close()
+				return false, err
+			}
+			if next == to {
+				rows.Close()
+				return true, nil
+			}
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// addDependency records that a task cannot be finished before another one.
+// Dependencies that would close a cycle are refused.
+func addDependency(c *gin.Context) {
+	taskID, ok := taskIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req addDependencyRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	dependsOn, ok := lookupTaskID(c, string(req.DependsOn))
+	if !ok {
+		return
+	}
+	if dependsOn == taskID {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "a task cannot depend on itself",
+		})
+		return
+	}
+
+	tx, err := db.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	for _, id := range []int{taskID, dependsOn} {
+		if _, err := fetchTask(tx, id); err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": fmt.Sprintf("task %d not found", id),
+				})
+			} else {
+				if respondTimedOut(c, err) {
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "failed to fetch task",
+				})
+			}
+			return
+		}
+	}
+
+	cycle, err := dependsOnTransitively(tx, dependsOn, taskID)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to check dependencies",
+		})
+		return
+	}
+	if cycle {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": fmt.Sprintf("task %d already depends on task %d", dependsOn, taskID),
+		})
+		return
+	}
+
+	result, err := tx.Exec("INSERT OR IGNORE INTO task_dependencies (task_id, depends_on_id) VALUES (?, ?)", taskID, dependsOn)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to add dependency",
+		})
+		return
+	}
+	if added, _ := result.RowsAffected(); added > 0 {
+		if err := recordAudit(tx, taskID, "dependency_added", requestActor(c), gin.H{"depends_on": dependsOn}); err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to record audit entry",
+			})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to commit transaction",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"task_id":    taskID,
+		"depends_on": dependsOn,
+	})
+}
+
+func removeDependency(c *gin.Context) {
+	taskID, ok := taskIDParam(c)
+	if !ok {
+		return
+	}
+	dependsOn, ok := lookupTaskID(c, c.Param("dependsOn"))
+	if !ok {
+		return
+	}
+
+	tx, err := db.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM task_dependencies WHERE task_id = ? AND depends_on_id = ?", taskID, dependsOn)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to remove dependency",
+		})
+		return
+	}
+	if removed, _ := result.RowsAffected(); removed == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "dependency not found",
+		})
+		return
+	}
+	if err := recordAudit(tx, taskID, "dependency_removed", requestActor(c), gin.H{"depends_on": dependsOn}); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to record audit entry",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to commit transaction",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "dependency removed successfully",
+	})
+}
+
+// dependencyTask summarizes a task on the other end of a dependency.
+type dependencyTask struct {
+	ID       int    `json:"id"`
+	PublicID string `json:"public_id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Done     bool   `json:"done"`
+}
+
+// relatedTasks returns the live tasks joined to taskID through
+// task_dependencies. column is the side taskID is on, and must be a
+// trusted name.
+func relatedTasks(q queryer, taskID int, column string) ([]dependencyTask, error) {
+	other := "depends_on_id"
+	if column == "depends_on_id" {
+		other = "task_id"
+	}
+
+	rows, err := q.Query(`SELECT t.id, t.public_id, t.title, t.status FROM task_dependencies d
+		JOIN tasks t ON t.id = d.`+other+` AND t.deleted_at IS NULL
+		WHERE d.`+column+` = ? ORDER BY t.id`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	wf := currentWorkflow()
+	tasks := []dependencyTask{}
+	for rows.Next() {
+		var t dependencyTask
+		if err := rows.Scan(&t.ID, &t.PublicID, &t.Title, &t.Status); err != nil {
+			return nil, err
+		}
+		t.Done = wf.IsDone(t.Status)
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// openDependencyIDs returns the live tasks taskID depends on that are not
+// done yet. A task cannot be moved to a done status while there are any.
+func openDependencyIDs(q queryer, taskID int) ([]int, error) {
+	deps, err := relatedTasks(q, taskID, "task_id")
+	if err != nil {
+		return nil, err
+	}
+	var open []int
+	for _, t := range deps {
+		if !t.Done {
+			open = append(open, t.ID)
+		}
+	}
+	return open, nil
+}
+
+// respondBlocked refuses a move to a done status. taskIDs are the open
+// dependencies of a single task, or the blocked tasks of a bulk update.
+func respondBlocked(c *gin.Context, taskIDs []int) {
+	c.JSON(http.StatusConflict, gin.H{
+		"error":    "blocked by open dependencies",
+		"task_ids": taskIDs,
+	})
+}
+
+// getBlockedBy lists the tasks a task is waiting on.
+func getBlockedBy(c *gin.Context) {
+	respondRelatedTasks(c, "task_id", "blocked_by")
+}
+
+// getBlocks lists the tasks waiting on a task, i.e. those affected when it
+// is finished or abandoned.
+func getBlocks(c *gin.Context) {
+	respondRelatedTasks(c, "depends_on_id", "blocks")
+}
+
+func respondRelatedTasks(c *gin.Context, column, key string) {
+	taskID, ok := taskIDParam(c)
+	if !ok {
+		return
+	}
+
+	if _, err := fetchTask(requestDB(c), taskID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "task not found",
+			})
+		} else {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to fetch task",
+			})
+		}
+		return
+	}
+
+	tasks, err := relatedTasks(requestDB(c), taskID, column)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch dependencies",
+		})
+		return
+	}
+
+	open := 0
+	for _, t := range tasks {
+		if !t.Done {
+			open++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"task_id": taskID,
+		key:       tasks,
+		"open":    open,
+	})
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func addTestDependency(t *testing.T, router http.Handler, taskID, dependsOn int) {
+	t.Helper()
+	w := doRequest(router, http.MethodPost, taskLocation(taskID)+"/dependencies", fmt.Sprintf(`{"depends_on":%d}`, dependsOn))
+	expectStatus(t, w, http.StatusCreated)
+}
+
+func TestGetTaskGraph(t *testing.T) {
+	router := newTestServer(t)
+	a := createTestTask(t, router, `{"title":"a"}`)
+	b := createTestTask(t, router, `{"title":"b \"quoted\""}`)
+	c := createTestTask(t, router, `{"title":"c","status":"done"}`)
+	addTestDependency(t, router, b.ID, a.ID)
+	addTestDependency(t, router, a.ID, c.ID)
+
+	w := doRequest(router, http.MethodPost, taskLocation(a.ID)+"/dependencies", fmt.Sprintf(`{"depends_on":%d}`, b.ID))
+	expectStatus(t, w, http.StatusConflict)
+
+	w = doRequest(router, http.MethodGet, "/tasks/graph?status=todo", "")
+	expectStatus(t, w, http.StatusOK)
+	var graph taskGraph
+	decodeBody(t, w, &graph)
+	if len(graph.Nodes) != 2 || len(graph.Edges) != 1 || graph.Edges[0] != (graphEdge{From: b.ID, To: a.ID}) {
+		t.Fatalf("graph = %+v, want the two todo tasks and the edge between them", graph)
+	}
+	if len(graph.Cycles) != 0 {
+		t.Errorf("cycles = %v, want none", graph.Cycles)
+	}
+
+	// Cycles can only come from rows written around the API.
+	if _, err := db.Exec("INSERT INTO task_dependencies (task_id, depends_on_id) VALUES (?, ?)", c.ID, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	w = doRequest(router, http.MethodGet, "/tasks/graph", "")
+	graph = taskGraph{}
+	decodeBody(t, w, &graph)
+	if len(graph.Cycles) != 1 || !slices.Equal(graph.Cycles[0], []int{a.ID, b.ID, c.ID}) {
+		t.Errorf("cycles = %v, want one through all three tasks", graph.Cycles)
+	}
+
+	w = doRequest(router, http.MethodGet, "/tasks/graph?format=dot", "")
+	expectStatus(t, w, http.StatusOK)
+	dot := w.Body.String()
+	for _, want := range []string{"digraph tasks {", fmt.Sprintf("t%d -> t%d [color=red];", b.ID, a.ID), `b \"quoted\"`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("dot output is missing %q:\n%s", want, dot)
+		}
+	}
+
+	w = doRequest(router, http.MethodGet, "/tasks/graph?format=svg", "")
+	expectStatus(t, w, http.StatusBadRequest)
+}
+
+func TestGetTaskGraphMaxNodes(t *testing.T) {
+	router := newTestServer(t, "GRAPH_MAX_NODES=1")
+	createTestTask(t, router, `{"title":"a"}`)
+	createTestTask(t, router, `{"title":"b"}`)
+	w := doRequest(router, http.MethodGet, "/tasks/graph", "")
+	expectStatus(t, w, http.StatusUnprocessableEntity)
+}
+
+func TestOpenDependenciesBlockCompletion(t *testing.T) {
+	router := newTestServer(t)
+	blocker := createTestTask(t, router, `{"title":"blocker"}`)
+	task := createTestTask(t, router, `{"title":"blocked"}`)
+	addTestDependency(t, router, task.ID, blocker.ID)
+
+	w := doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"status":"done"}`)
+	expectStatus(t, w, http.StatusConflict)
+	var resp struct {
+		TaskIDs []int `json:"task_ids"`
+	}
+	decodeBody(t, w, &resp)
+	if !slices.Equal(resp.TaskIDs, []int{blocker.ID}) {
+		t.Errorf("task_ids = %v, want the blocker", resp.TaskIDs)
+	}
+
+	w = doRequest(router, http.MethodPost, "/tasks/bulk-update", fmt.Sprintf(`{"filter":{"ids":[%d]},"set":{"status":"done"}}`, task.ID))
+	expectStatus(t, w, http.StatusConflict)
+
+	// Finishing the blocker in the same update clears the way.
+	w = doRequest(router, http.MethodPost, "/tasks/bulk-update", fmt.Sprintf(`{"filter":{"ids":[%d,%d]},"set":{"status":"done"}}`, task.ID, blocker.ID))
+	expectStatus(t, w, http.StatusOK)
+	if got := getTestTask(t, router, task.ID); got.Status != "done" {
+		t.Errorf("status = %q, want done", got.Status)
+	}
+}
+
+func TestBlockedByAndBlocks(t *testing.T) {
+	router := newTestServer(t)
+	design := createTestTask(t, router, `{"title":"design","status":"done"}`)
+	api := createTestTask(t, router, `{"title":"api"}`)
+	ui := createTestTask(t, router, `{"title":"ui"}`)
+	dropped := createTestTask(t, router, `{"title":"dropped"}`)
+	addTestDependency(t, router, ui.ID, design.ID)
+	addTestDependency(t, router, ui.ID, api.ID)
+	addTestDependency(t, router, dropped.ID, api.ID)
+	doRequest(router, http.MethodDelete, taskLocation(dropped.ID), "")
+
+	related := func(path, key string) ([]dependencyTask, int) {
+		t.Helper()
+		w := doRequest(router, http.MethodGet, path, "")
+		expectStatus(t, w, http.StatusOK)
+		var resp map[string]json.RawMessage
+		decodeBody(t, w, &resp)
+		var tasks []dependencyTask
+		var open int
+		if err := json.Unmarshal(resp[key], &tasks); err != nil {
+			t.Fatalf("%s: %v", key, err)
+		}
+		json.Unmarshal(resp["open"], &open)
+		return tasks, open
+	}
+
+	tasks, open := related(taskLocation(ui.ID)+"/blocked-by", "blocked_by")
+	if len(tasks) != 2 || tasks[0].ID != design.ID || !tasks[0].Done || tasks[1].ID != api.ID || tasks[1].Status != "todo" || open != 1 {
+		t.Errorf("ui blocked by %+v (open %d), want done design and open api", tasks, open)
+	}
+	tasks, _ = related(taskLocation(api.ID)+"/blocks", "blocks")
+	if len(tasks) != 1 || tasks[0].ID != ui.ID || tasks[0].Title != "ui" {
+		t.Errorf("api blocks %+v, want only the live ui task", tasks)
+	}
+	if tasks, open := related(taskLocation(design.ID)+"/blocked-by", "blocked_by"); len(tasks) != 0 || open != 0 {
+		t.Errorf("design blocked by %+v, want none", tasks)
+	}
+
+	w := doRequest(router, http.MethodGet, "/task/999/blocks", "")
+	expectStatus(t, w, http.StatusNotFound)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fieldComparison struct {
+	A       any  `json:"a"`
+	B       any  `json:"b"`
+	Differs bool `json:"differs"`
+}
+
+// getTaskDiff compares two tasks field by field, e.g. to decide whether
+// they are duplicates worth merging. ?include=tags,subtasks adds the tag
+// sets and subtask titles to the comparison.
+func getTaskDiff(c *gin.Context) {
+	var ids [2]int
+	for i, key := range []string{"a", "b"} {
+		id, err := resolveTaskID(requestDB(c), c.Query(key))
+		switch {
+		case err == errInvalidTaskID:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("%s must be a task ID", key),
+			})
+			return
+		case err == sql.ErrNoRows:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": fmt.Sprintf("task %s not found", c.Query(key)),
+			})
+			return
+		case err != nil:
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to fetch task",
+			})
+			return
+		}
+		ids[i] = id
+	}
+
+	include := make(map[string]bool)
+	for _, part := range strings.Split(c.Query("include"), ",") {
+		switch part = strings.TrimSpace(part); part {
+		case "":
+		case "tags", "subtasks":
+			include[part] = true
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("cannot include %q, expected tags or subtasks", part),
+			})
+			return
+		}
+	}
+
+	var tasks [2]Task
+	for i, id := range ids {
+		task, err := fetchTask(requestDB(c), id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": fmt.Sprintf("task %d not found", id),
+				})
+			} else {
+				if respondTimedOut(c, err) {
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "failed to fetch task",
+				})
+			}
+			return
+		}
+		tasks[i] = task
+	}
+	a, b := tasks[0], tasks[1]
+
+	values := [][3]any{
+		{"title", a.Title, b.Title},
+		{"status", a.Status, b.Status},
+		{"priority", a.Priority, b.Priority},
+		{"position", a.Position, b.Position},
+		{"assignee", a.Assignee, b.Assignee},
+		{"owner", a.Owner, b.Owner},
+		{"due_date", a.DueDate, b.DueDate},
+		{"parent_id", a.ParentID, b.ParentID},
+	}
+	if include["tags"] {
+		values = append(values, [3]any{"tags", a.Tags, b.Tags})
+	}
+	if include["subtasks"] {
+		subA, err := subtaskTitles(requestDB(c), a.ID)
+		if err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to fetch subtasks",
+			})
+			return
+		}
+		subB, err := subtaskTitles(requestDB(c), b.ID)
+		if err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to fetch subtasks",
+			})
+			return
+		}
+		values = append(values, [3]any{"subtasks", subA, subB})
+	}
+
+	fields := make(map[string]fieldComparison, len(values))
+	differing := []string{}
+	for _, v := range values {
+		name := v[0].(string)
+		differs := !sameValue(v[1], v[2])
+		fields[name] = fieldComparison{A: v[1], B: v[2], Differs: differs}
+		if differs {
+			differing = append(differing, name)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"a":         a.ID,
+		"b":         b.ID,
+		"fields":    fields,
+		"differing": differing,
+	})
+}
+
+// sameValue compares field values, looking through pointers so that two
+// equal due dates or parent ids match.
+func sameValue(a, b any) bool {
+	switch av := a.(type) {
+	case *int:
+		return sameID(av, b.(*int))
+	case *time.Time:
+		return sameTime(av, b.(*time.Time))
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// subtaskTitles returns the sorted titles of a task's live subtasks.
+func subtaskTitles(q queryer, taskID int) ([]string, error) {
+	rows, err := q.Query("SELECT title FROM tasks WHERE parent_id = ? AND deleted_at IS NULL", taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := []string{}
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, err
+		}
+		titles = append(titles, title)
+	}
+	slices.Sort(titles)
+	return titles, rows.Err()
+}
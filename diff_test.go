@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestGetTaskDiff(t *testing.T) {
+	router := newTestServer(t)
+	a := createTestTask(t, router, `{"title":"Write report","priority":2,"assignee":"alice","due_date":"2026-11-01T00:00:00Z","tags":["docs","q4"]}`)
+	b := createTestTask(t, router, `{"title":"write report!","priority":4,"assignee":"alice","due_date":"2026-11-01T00:00:00Z","tags":["q4","docs"]}`)
+	createTestTask(t, router, fmt.Sprintf(`{"title":"outline","parent_id":%d}`, a.ID))
+
+	w := doRequest(router, http.MethodGet, fmt.Sprintf("/tasks/diff?a=%d&b=%d&include=tags,subtasks", a.ID, b.ID), "")
+	expectStatus(t, w, http.StatusOK)
+	var resp struct {
+		Fields    map[string]fieldComparison `json:"fields"`
+		Differing []string                   `json:"differing"`
+	}
+	decodeBody(t, w, &resp)
+
+	for _, field := range []string{"title", "priority", "subtasks"} {
+		if !resp.Fields[field].Differs || !slices.Contains(resp.Differing, field) {
+			t.Errorf("%s should differ: %+v", field, resp.Fields[field])
+		}
+	}
+	for _, field := range []string{"status", "assignee", "due_date", "tags", "parent_id"} {
+		if resp.Fields[field].Differs || slices.Contains(resp.Differing, field) {
+			t.Errorf("%s should match: %+v", field, resp.Fields[field])
+		}
+	}
+
+	w = doRequest(router, http.MethodGet, fmt.Sprintf("/tasks/diff?a=%d&b=999", a.ID), "")
+	expectStatus(t, w, http.StatusNotFound)
+	w = doRequest(router, http.MethodGet, fmt.Sprintf("/tasks/diff?a=%d&b=%d&include=comments", a.ID, b.ID), "")
+	expectStatus(t, w, http.StatusBadRequest)
+}
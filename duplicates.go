@@ -0,0 +1,129 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+const (
+	duplicateCheckOff        = "off"
+	duplicateCheckExact      = "exact"
+	duplicateCheckNormalized = "normalized"
+)
+
+// duplicateCheckMode is set from DUPLICATE_TITLE_CHECK at startup.
+var duplicateCheckMode = duplicateCheckOff
+
+// taskWarning is a non-fatal note returned alongside a saved task.
+type taskWarning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	TaskID  int    `json:"task_id,omitempty"`
+}
+
+// createdTask is the response to a create: the task itself plus any
+// warnings about it.
+type createdTask struct {
+	Task
+	Warnings []taskWarning `json:"warnings,omitempty"`
+}
+
+// loadDuplicateCheck reads DUPLICATE_TITLE_CHECK, defaulting to off.
+func loadDuplicateCheck() (string, error) {
+	mode := os.Getenv("DUPLICATE_TITLE_CHECK")
+	switch mode {
+	case "":
+		return duplicateCheckOff, nil
+	case duplicateCheckOff, duplicateCheckExact, duplicateCheckNormalized:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("DUPLICATE_TITLE_CHECK must be %q, %q or %q", duplicateCheckOff, duplicateCheckExact, duplicateCheckNormalized)
+	}
+}
+
+func initDuplicateCheck() error {
+	mode, err := loadDuplicateCheck()
+	if err != nil {
+		return err
+	}
+	duplicateCheckMode = mode
+	return nil
+}
+
+// comparableTitle lowercases a title and drops punctuation and repeated
+// whitespace, so "Fix login bug!" and "fix  login bug" compare equal.
+func comparableTitle(title string) string {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(title)) {
+		word = strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) })
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// backfillNormalizedTitles fills normalized_title, the indexed
+// comparableTitle of each title, for tasks stored before it existed.
+func backfillNormalizedTitles() error {
+	rows, err := db.Query("SELECT id, title FROM tasks WHERE normalized_title IS NULL")
+	if err != nil {
+		return err
+	}
+
+	titles := make(map[int]string)
+	for rows.Next() {
+		var (
+			id    int
+			title string
+		)
+		if err := rows.Scan(&id, &title); err != nil {
+			rows.Close()
+			return err
+		}
+		titles[id] = title
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, title := range titles {
+		if _, err := db.Exec("UPDATE tasks SET normalized_title = ? WHERE id = ?", comparableTitle(title), id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findDuplicateTitle returns the id of an existing task whose title matches
+// title under the configured mode, or 0 when there is none or the check is
+// off.
+func findDuplicateTitle(q queryer, title string) (int, error) {
+	switch duplicateCheckMode {
+	case duplicateCheckExact:
+		var id int
+		err := q.QueryRow("SELECT id FROM tasks WHERE title = ? AND deleted_at IS NULL ORDER BY id LIMIT 1", title).Scan(&id)
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return id, err
+	case duplicateCheckNormalized:
+		want := comparableTitle(title)
+		if want == "" {
+			return 0, nil
+		}
+
+		var id int
+		err := q.QueryRow("SELECT id FROM tasks WHERE normalized_title = ? AND deleted_at IS NULL ORDER BY id LIMIT 1", want).Scan(&id)
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return id, err
+	default:
+		return 0, nil
+	}
+}
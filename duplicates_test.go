@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func createWithWarnings(t *testing.T, router http.Handler, body string) createdTask {
+	t.Helper()
+	w := doRequest(router, http.MethodPost, "/task", body)
+	expectStatus(t, w, http.StatusCreated)
+	var created createdTask
+	decodeBody(t, w, &created)
+	return created
+}
+
+func TestDuplicateTitleWarning(t *testing.T) {
+	tests := []struct {
+		mode  string
+		title string
+		warns bool
+	}{
+		{duplicateCheckOff, "Fix login bug", false},
+		{duplicateCheckExact, "Fix login bug", true},
+		{duplicateCheckExact, "fix login bug", false},
+		{duplicateCheckNormalized, "fix  LOGIN bug!", true},
+		{duplicateCheckNormalized, "fix logout bug", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode+"/"+tt.title, func(t *testing.T) {
+			router := newTestServer(t, "DUPLICATE_TITLE_CHECK="+tt.mode)
+			original := createTestTask(t, router, `{"title":"Fix login bug"}`)
+
+			created := createWithWarnings(t, router, `{"title":"`+tt.title+`"}`)
+			if created.ID == 0 {
+				t.Fatal("task was not created")
+			}
+			warned := len(created.Warnings) == 1 && created.Warnings[0].TaskID == original.ID
+			if warned != tt.warns || (!tt.warns && len(created.Warnings) > 0) {
+				t.Errorf("warnings = %+v, want warning: %v", created.Warnings, tt.warns)
+			}
+		})
+	}
+}
+
+func TestDuplicateTitleFollowsEdits(t *testing.T) {
+	router := newTestServer(t, "DUPLICATE_TITLE_CHECK=normalized")
+	task := createTestTask(t, router, `{"title":"draft"}`)
+	w := doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"title":"Ship release"}`)
+	expectStatus(t, w, http.StatusOK)
+
+	if created := createWithWarnings(t, router, `{"title":"ship release."}`); len(created.Warnings) != 1 {
+		t.Errorf("warnings = %+v, want one for the renamed task", created.Warnings)
+	}
+	if created := createWithWarnings(t, router, `{"title":"Draft"}`); len(created.Warnings) != 0 {
+		t.Errorf("warnings = %+v, want none for the old title", created.Warnings)
+	}
+}
+
+func TestBackfillNormalizedTitles(t *testing.T) {
+	router := newTestServer(t)
+	task := createTestTask(t, router, `{"title":"Fix   the BUG!"}`)
+	if _, err := db.Exec("UPDATE tasks SET normalized_title = NULL"); err != nil {
+		t.Fatal(err)
+	}
+	if err := backfillNormalizedTitles(); err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	if err := db.QueryRow("SELECT normalized_title FROM tasks WHERE id = ?", task.ID).Scan(&got); err != nil || got != "fix the bug" {
+		t.Errorf("normalized_title = %q, %v; want %q", got, err, "fix the bug")
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// alwaysEnvelope is set from ALWAYS_ENVELOPE at startup.
+var alwaysEnvelope bool
+
+// listMeta describes a list response. Limit and Offset are only set for
+// paginated lists.
+type listMeta struct {
+	Total  int  `json:"total"`
+	Limit  *int `json:"limit,omitempty"`
+	Offset *int `json:"offset,omitempty"`
+}
+
+func pageMeta(total int, page pagination) listMeta {
+	return listMeta{Total: total, Limit: &page.Limit, Offset: &page.Offset}
+}
+
+// wantsEnvelope decides whether a list is wrapped as {data, meta}. The
+// envelope query parameter wins, then meta, then the ALWAYS_ENVELOPE
+// default; values that are not booleans are ignored.
+func wantsEnvelope(c *gin.Context) bool {
+	for _, key := range []string{"envelope", "meta"} {
+		if v, err := strconv.ParseBool(c.Query(key)); err == nil {
+			return v
+		}
+	}
+	return alwaysEnvelope
+}
+
+// respondList writes a successful list response, enveloped or as a bare
+// array. Errors are never enveloped and keep their {"error": ...} shape.
+func respondList(c *gin.Context, items any, meta listMeta) {
+	if wantsEnvelope(c) {
+		c.JSON(http.StatusOK, gin.H{
+			"data": items,
+			"meta": meta,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestListEnvelope(t *testing.T) {
+	tests := []struct {
+		env, query string
+		enveloped  bool
+	}{
+		{"ALWAYS_ENVELOPE=false", "", false},
+		{"ALWAYS_ENVELOPE=false", "?meta=true", true},
+		{"ALWAYS_ENVELOPE=true", "", true},
+		{"ALWAYS_ENVELOPE=true", "?envelope=false", false},
+		{"ALWAYS_ENVELOPE=true", "?envelope=false&meta=true", false},
+		{"ALWAYS_ENVELOPE=false", "?envelope=maybe", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env+tt.query, func(t *testing.T) {
+			router := newTestServer(t, tt.env)
+			createTestTask(t, router, `{"title":"a"}`)
+
+			w := doRequest(router, http.MethodGet, "/tasks"+tt.query, "")
+			expectStatus(t, w, http.StatusOK)
+			if !tt.enveloped {
+				var tasks []Task
+				decodeBody(t, w, &tasks)
+				if len(tasks) != 1 {
+					t.Errorf("tasks = %+v, want a bare array of one", tasks)
+				}
+				return
+			}
+			var resp struct {
+				Data []Task   `json:"data"`
+				Meta listMeta `json:"meta"`
+			}
+			decodeBody(t, w, &resp)
+			if len(resp.Data) != 1 || resp.Meta.Total != 1 {
+				t.Errorf("envelope = %+v, want one task and total 1", resp)
+			}
+		})
+	}
+}
+
+func TestListEnvelopeEmptyAndErrors(t *testing.T) {
+	router := newTestServer(t, "ALWAYS_ENVELOPE=true")
+
+	w := doRequest(router, http.MethodGet, "/tasks?envelope=false", "")
+	if got := strings.TrimSpace(w.Body.String()); got != "[]" {
+		t.Errorf("empty list = %s, want []", got)
+	}
+	w = doRequest(router, http.MethodGet, "/tasks", "")
+	if got := strings.TrimSpace(w.Body.String()); !strings.HasPrefix(got, `{"data":[],`) {
+		t.Errorf("empty envelope = %s, want data []", got)
+	}
+
+	w = doRequest(router, http.MethodGet, "/tasks?priority_min=9", "")
+	expectStatus(t, w, http.StatusBadRequest)
+	var resp map[string]any
+	decodeBody(t, w, &resp)
+	if _, ok := resp["error"]; !ok || len(resp) != 1 {
+		t.Errorf("error body = %v, want only an error field", resp)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type taskETA struct {
+	TaskID          int        `json:"task_id"`
+	Remaining       int        `json:"remaining"`
+	Samples         int        `json:"samples"`
+	AverageDuration string     `json:"average_duration,omitempty"`
+	AverageSeconds  float64    `json:"average_seconds,omitempty"`
+	ETA             *time.Time `json:"eta"`
+	Message         string     `json:"message,omitempty"`
+}
+
+// getTaskETA estimates when a task will be finished. Remaining work is the
+// number of unfinished subtasks, or the task itself when it has none, and
+// each unit is assumed to take the average time from creation to first
+// completion seen across the audit log. It is a planning heuristic only.
+func getTaskETA(c *gin.Context) {
+	taskID, ok := taskIDParam(c)
+	if !ok {
+		return
+	}
+
+	task, err := fetchTask(requestDB(c), taskID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "task not found",
+			})
+		} else {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to fetch task",
+			})
+		}
+		return
+	}
+
+	wf := currentWorkflow()
+	remaining, err := remainingWork(requestDB(c), task, wf)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count subtasks",
+		})
+		return
+	}
+
+	eta := taskETA{TaskID: task.ID, Remaining: remaining}
+	if remaining == 0 {
+		eta.Message = "task is already done"
+		c.JSON(http.StatusOK, eta)
+		return
+	}
+
+	avg, samples, err := averageTimeToDone(requestDB(c), wf)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to read completion history",
+		})
+		return
+	}
+	eta.Samples = samples
+	if samples == 0 {
+		eta.Message = "no completion history to estimate from"
+		c.JSON(http.StatusOK, eta)
+		return
+	}
+
+	at := time.Now().Add(time.Duration(remaining) * avg).UTC().Truncate(time.Second)
+	eta.ETA = &at
+	eta.AverageDuration = avg.Round(time.Second).String()
+	eta.AverageSeconds = avg.Seconds()
+
+	c.JSON(http.StatusOK, eta)
+}
+
+func remainingWork(q queryer, task Task, wf *Workflow) (int, error) {
+	rows, err := q.Query("SELECT status FROM tasks WHERE parent_id = ? AND deleted_at IS NULL", task.ID)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	subtasks, open := 0, 0
+	for rows.Next() {
+		var status string
+		if err := rows.Scan(&status); err != nil {
+			return 0, err
+		}
+		subtasks++
+		if !wf.IsDone(status) {
+			open++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if subtasks > 0 {
+		return open, nil
+	}
+	if wf.IsDone(task.Status) {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// averageTimeToDone measures, for every task in the audit log that was
+// created and later moved to a done status, the time between the two events.
+func averageTimeToDone(q queryer, wf *Workflow) (time.Duration, int, error) {
+	if len(wf.Done) == 0 {
+		return 0, 0, nil
+	}
+
+	args := make([]any, len(wf.Done))
+	for i, s := range wf.Done {
+		args[i] = s
+	}
+
+	rows, err := q.Query(`SELECT c.created_at, MIN(u.created_at) FROM task_audit c
+		JOIN task_audit u ON u.task_id = c.task_id AND u.action = 'updated'
+			AND json_extract(u.detail, '$.status.to') IN (`+placeholders(len(args))+`)
+		WHERE c.action = 'created'
+		GROUP BY c.task_id`, args...)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		total   time.Duration
+		samples int
+	)
+	for rows.Next() {
+		var createdAt, doneAt string
+		if err := rows.Scan(&createdAt, &doneAt); err != nil {
+			return 0, 0, err
+		}
+		start, err1 := time.Parse(dbTimeLayout, createdAt)
+		end, err2 := time.Parse(dbTimeLayout, doneAt)
+		if err1 != nil || err2 != nil || end.Before(start) {
+			continue
+		}
+		total += end.Sub(start)
+		samples++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	if samples == 0 {
+		return 0, 0, nil
+	}
+	return total / time.Duration(samples), samples, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func getTestETA(t *testing.T, router http.Handler, id int) taskETA {
+	t.Helper()
+	w := doRequest(router, http.MethodGet, taskLocation(id)+"/eta", "")
+	expectStatus(t, w, http.StatusOK)
+	var eta taskETA
+	decodeBody(t, w, &eta)
+	return eta
+}
+
+func TestGetTaskETA(t *testing.T) {
+	router := newTestServer(t)
+	parent := createTestTask(t, router, `{"title":"parent"}`)
+	for i := 0; i < 3; i++ {
+		createTestTask(t, router, fmt.Sprintf(`{"title":"sub %d","parent_id":%d}`, i, parent.ID))
+	}
+
+	eta := getTestETA(t, router, parent.ID)
+	if eta.Remaining != 3 || eta.Samples != 0 || eta.ETA != nil {
+		t.Fatalf("eta = %+v, want 3 remaining and no history", eta)
+	}
+
+	done := createTestTask(t, router, `{"title":"quick"}`)
+	doRequest(router, http.MethodPatch, taskLocation(done.ID), `{"status":"done"}`)
+	if eta := getTestETA(t, router, done.ID); eta.Remaining != 0 || eta.Message == "" {
+		t.Errorf("done task eta = %+v, want nothing remaining", eta)
+	}
+
+	eta = getTestETA(t, router, parent.ID)
+	if eta.Samples != 1 || eta.ETA == nil {
+		t.Fatalf("eta = %+v, want an estimate from one sample", eta)
+	}
+
+	w := doRequest(router, http.MethodGet, "/task/999/eta", "")
+	expectStatus(t, w, http.StatusNotFound)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// taskETag is a strong validator for a task's JSON representation.
+func taskETag(task Task) string {
+	data, _ := json.Marshal(task)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified sets the ETag header and answers 304 when the request's
+// If-None-Match already names it.
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+	for _, candidate := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeadTask(t *testing.T) {
+	router := newTestServer(t)
+	task := createTestTask(t, router, `{"title":"probe"}`)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	get := doRequest(router, http.MethodGet, taskLocation(task.ID), "")
+	expectStatus(t, get, http.StatusOK)
+	etag := get.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("GET sent no ETag")
+	}
+
+	for _, tt := range []struct {
+		path string
+		want int
+	}{
+		{taskLocation(task.ID), http.StatusOK},
+		{"/task/999", http.StatusNotFound},
+	} {
+		resp, err := http.Head(server.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.want || len(body) != 0 {
+			t.Errorf("HEAD %s = %d with %d body bytes, want %d and none", tt.path, resp.StatusCode, len(body), tt.want)
+		}
+		if tt.want == http.StatusOK && resp.Header.Get("ETag") != etag {
+			t.Errorf("HEAD ETag = %q, want GET's %q", resp.Header.Get("ETag"), etag)
+		}
+	}
+}
+
+func TestGetTaskIfNoneMatch(t *testing.T) {
+	router := newTestServer(t)
+	task := createTestTask(t, router, `{"title":"probe"}`)
+	etag := doRequest(router, http.MethodGet, taskLocation(task.ID), "").Header().Get("ETag")
+
+	w := doRequest(router, http.MethodGet, taskLocation(task.ID), "", "If-None-Match: W/"+etag)
+	expectStatus(t, w, http.StatusNotModified)
+
+	doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"priority":2}`)
+	w = doRequest(router, http.MethodGet, taskLocation(task.ID), "", "If-None-Match: "+etag)
+	expectStatus(t, w, http.StatusOK)
+	if w.Header().Get("ETag") == etag {
+		t.Error("ETag did not change after an update")
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamConnections counts currently open streaming subscribers.
+var streamConnections atomic.Int64
+
+// limitStreams rejects new streaming connections once max are open, so a
+// burst of subscribers cannot exhaust memory. A max of zero or less means
+// unlimited.
+func limitStreams(max int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if n := streamConnections.Add(1); max > 0 && n > int64(max) {
+			streamConnections.Add(-1)
+			setRetryAfter(c, retryAfter.Streams)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error": "too many streaming connections",
+			})
+			return
+		}
+		defer streamConnections.Add(-1)
+
+		c.Next()
+	}
+}
+
+type taskEvent struct {
+	ID        int64           `json:"id"`
+	TaskID    int             `json:"task_id"`
+	Action    string          `json:"action"`
+	Actor     string          `json:"actor"`
+	Detail    json.RawMessage `json:"detail,omitempty"`
+	CreatedAt string          `json:"created_at"`
+}
+
+// streamTaskEvents sends task audit entries to the client as server-sent
+// events as they are committed. Clients resume after a reconnect by sending
+// the last event id back in Last-Event-ID; new subscribers only receive
+// events from the time they connect.
+func streamTaskEvents(pollInterval time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lastID, err := strconv.ParseInt(c.GetHeader("Last-Event-ID"), 10, 64)
+		if err != nil {
+			if err := db.QueryRowContext(c.Request.Context(), "SELECT COALESCE(MAX(id), 0) FROM task_audit").Scan(&lastID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "failed to start event stream",
+				})
+				return
+			}
+		}
+
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case <-c.Request.Context().Done():
+				return false
+			case <-ticker.C:
+			}
+
+			events, err := taskEventsAfter(requestDB(c), lastID)
+			if err != nil {
+				c.SSEvent("error", gin.H{"error": "failed to fetch events"})
+				return false
+			}
+			for _, e := range events {
+				data, err := json.Marshal(e)
+				if err != nil {
+					return false
+				}
+				fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Action, data)
+				lastID = e.ID
+			}
+			return true
+		})
+	}
+}
+
+func taskEventsAfter(q queryer, lastID int64) ([]taskEvent, error) {
+	rows, err := q.Query(`SELECT id, task_id, action, actor, COALESCE(detail, ''), created_at
+		FROM task_audit WHERE id > ? ORDER BY id LIMIT 100`, lastID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []taskEvent
+	for rows.Next() {
+		var (
+			e      taskEvent
+			detail string
+		)
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.Action, &e.Actor, &detail, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if detail != "" {
+			e.Detail = json.RawMessage(detail)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
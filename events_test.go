@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStreamConnectionLimit(t *testing.T) {
+	router := newTestServer(t, "MAX_STREAM_CONNECTIONS=1", "EVENTS_POLL_INTERVAL=10ms", "RETRY_AFTER_STREAMS=7s")
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	first, err := http.Get(server.URL + "/tasks/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.StatusCode != http.StatusOK {
+		t.Fatalf("first stream status = %d, want 200", first.StatusCode)
+	}
+
+	second, err := http.Get(server.URL + "/tasks/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Body.Close()
+	if second.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("second stream status = %d, want 503", second.StatusCode)
+	}
+	if got := second.Header.Get("Retry-After"); got != "7" {
+		t.Errorf("Retry-After = %q, want 7", got)
+	}
+	if n := streamConnections.Load(); n != 1 {
+		t.Errorf("open streams = %d, want 1", n)
+	}
+
+	first.Body.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for streamConnections.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("open streams = %d after disconnect, want 0", streamConnections.Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// taskFilter selects a subset of tasks. The zero value matches every task.
+type taskFilter struct {
+	IDs      []int  `json:"ids"`
+	Status   string `json:"status"`
+	Priority *int   `json:"priority"`
+	Assignee string `json:"assignee"`
+
+	// Unassigned matches tasks nobody is assigned to.
+	Unassigned bool `json:"unassigned"`
+
+	PriorityMin *int `json:"priority_min"`
+	PriorityMax *int `json:"priority_max"`
+
+	// IncludeDeleted also matches soft-deleted tasks, which are hidden by default.
+	IncludeDeleted bool `json:"include_deleted"`
+
+	// excludeStatuses is set by handlers rather than clients, e.g. to skip
+	// finished work.
+	excludeStatuses []string
+}
+
+// isEmpty reports whether the filter narrows nothing. IncludeDeleted only
+// widens the match, so a filter with nothing else set is still empty and
+// the bulk endpoints keep requiring all=true for it.
+func (f taskFilter) isEmpty() bool {
+	return len(f.IDs) == 0 && f.Status == "" && f.Priority == nil && f.Assignee == "" &&
+		!f.Unassigned && f.PriorityMin == nil && f.PriorityMax == nil
+}
+
+// where builds a parameterised WHERE clause, including the leading keyword,
+// or an empty string when the filter matches everything.
+func (f taskFilter) where() (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+
+	if !f.IncludeDeleted {
+		conds = append(conds, "deleted_at IS NULL")
+	}
+	if len(f.IDs) > 0 {
+		conds = append(conds, "id IN ("+placeholders(len(f.IDs))+")")
+		for _, id := range f.IDs {
+			args = append(args, id)
+		}
+	}
+	if f.Status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, f.Status)
+	}
+	if f.Priority != nil {
+		conds = append(conds, "priority = ?")
+		args = append(args, *f.Priority)
+	}
+	switch {
+	case f.PriorityMin != nil && f.PriorityMax != nil:
+		conds = append(conds, "priority BETWEEN ? AND ?")
+		args = append(args, *f.PriorityMin, *f.PriorityMax)
+	case f.PriorityMin != nil:
+		conds = append(conds, "priority >= ?")
+		args = append(args, *f.PriorityMin)
+	case f.PriorityMax != nil:
+		conds = append(conds, "priority <= ?")
+		args = append(args, *f.PriorityMax)
+	}
+	if f.Assignee != "" {
+		conds = append(conds, "assignee = ?")
+		args = append(args, f.Assignee)
+	}
+	if f.Unassigned {
+		conds = append(conds, "assignee = ''")
+	}
+	if len(f.excludeStatuses) > 0 {
+		conds = append(conds, "status NOT IN ("+placeholders(len(f.excludeStatuses))+")")
+		for _, s := range f.excludeStatuses {
+			args = append(args, s)
+		}
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// placeholders returns n comma-separated SQL bind parameters.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func (f taskFilter) validate() error {
+	for _, p := range []*int{f.Priority, f.PriorityMin, f.PriorityMax} {
+		if p != nil && !validPriority(*p) {
+			return fmt.Errorf("priority must be between %d and %d", minPriority, maxPriority)
+		}
+	}
+	if f.PriorityMin != nil && f.PriorityMax != nil && *f.PriorityMin > *f.PriorityMax {
+		return errors.New("priority_min must not be greater than priority_max")
+	}
+	if f.Unassigned && f.Assignee != "" {
+		return errors.New("assignee and unassigned cannot be combined")
+	}
+	return nil
+}
+
+// taskFilterFromQuery reads filter fields from the request's query string.
+func taskFilterFromQuery(c *gin.Context) (taskFilter, error) {
+	var f taskFilter
+
+	f.Status = c.Query("status")
+	f.IncludeDeleted = c.Query("include_deleted") == "true"
+	f.Assignee = c.Query("assignee")
+	f.Unassigned = c.Query("unassigned") == "true"
+
+	for _, param := range []struct {
+		key  string
+		dest **int
+	}{
+		{"priority", &f.Priority},
+		{"priority_min", &f.PriorityMin},
+		{"priority_max", &f.PriorityMax},
+	} {
+		raw := c.Query(param.key)
+		if raw == "" {
+			continue
+		}
+		p, err := strconv.Atoi(raw)
+		if err != nil {
+			return f, fmt.Errorf("%s must be an integer", param.key)
+		}
+		*param.dest = &p
+	}
+
+	if raw := c.Query("ids"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return f, errors.New("invalid task ID in ids")
+			}
+			f.IDs = append(f.IDs, id)
+		}
+	}
+
+	return f, f.validate()
+}
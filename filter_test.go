@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestGetTasksPriorityRange(t *testing.T) {
+	router := newTestServer(t)
+	ids := map[int]int{}
+	for p := 1; p <= 4; p++ {
+		status := "todo"
+		if p == 3 {
+			status = "done"
+		}
+		task := createTestTask(t, router, fmt.Sprintf(`{"title":"p%d","status":%q,"priority":%d}`, p, status, p))
+		ids[task.ID] = p
+	}
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"priority_min=2&priority_max=3", []int{2, 3}},
+		{"priority_min=3", []int{3, 4}},
+		{"priority_max=1", []int{1}},
+		{"priority_min=2&priority_max=3&status=todo", []int{2}},
+	}
+	for _, tt := range tests {
+		w := doRequest(router, http.MethodGet, "/tasks?"+tt.query, "")
+		expectStatus(t, w, http.StatusOK)
+		var tasks []Task
+		decodeBody(t, w, &tasks)
+		var got []int
+		for _, task := range tasks {
+			got = append(got, ids[task.ID])
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: priorities = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestGetTasksPriorityRangeRejectsInvalid(t *testing.T) {
+	router := newTestServer(t)
+	for _, query := range []string{
+		"priority_min=3&priority_max=2",
+		"priority_min=-1",
+		"priority_max=6",
+		"priority_min=high",
+	} {
+		w := doRequest(router, http.MethodGet, "/tasks?"+query, "")
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, w.Code)
+		}
+	}
+}
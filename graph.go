@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type graphNode struct {
+	ID      int    `json:"id"`
+	Title   string `json:"title"`
+	Status  string `json:"status"`
+	InCycle bool   `json:"in_cycle"`
+}
+
+// graphEdge points from a task to a task it depends on.
+type graphEdge struct {
+	From    int  `json:"from"`
+	To      int  `json:"to"`
+	InCycle bool `json:"in_cycle"`
+}
+
+type taskGraph struct {
+	Nodes  []graphNode `json:"nodes"`
+	Edges  []graphEdge `json:"edges"`
+	Cycles [][]int     `json:"cycles"`
+}
+
+// markCycles finds the strongly connected components of the graph; every
+// component with more than one task, or a task depending on itself, is a
+// cycle. Adding dependencies refuses cycles, so this only finds ones
+// written some other way.
+func (g *taskGraph) markCycles() {
+	adj := make(map[int][]int)
+	for _, e := range g.Edges {
+		adj[e.From] = append(adj[e.From], e.To)
+	}
+
+	var (
+		index   = make(map[int]int)
+		low     = make(map[int]int)
+		onStack = make(map[int]bool)
+		stack   []int
+		next    int
+		comp    = make(map[int]int)
+		cycles  = [][]int{}
+	)
+	var visit func(v int)
+	visit = func(v int) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range adj[v] {
+			if _, seen := index[w]; !seen {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+
+		if low[v] == index[v] {
+			var members []int
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				members = append(members, w)
+				if w == v {
+					break
+				}
+			}
+			for _, w := range members {
+				comp[w] = v
+			}
+			if len(members) > 1 {
+				sort.Ints(members)
+				cycles = append(cycles, members)
+			}
+		}
+	}
+	for _, n := range g.Nodes {
+		if _, seen := index[n.ID]; !seen {
+			visit(n.ID)
+		}
+	}
+
+	inCycle := make(map[int]bool)
+	for i, e := range g.Edges {
+		if comp[e.From] == comp[e.To] {
+			g.Edges[i].InCycle = true
+			inCycle[e.From], inCycle[e.To] = true, true
+			if e.From == e.To {
+				cycles = append(cycles, []int{e.From})
+			}
+		}
+	}
+	for i, n := range g.Nodes {
+		g.Nodes[i].InCycle = inCycle[n.ID]
+	}
+
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	g.Cycles = cycles
+}
+
+// dot renders the graph in Graphviz DOT, with cycles drawn in red.
+func (g *taskGraph) dot() string {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+	var b strings.Builder
+	b.WriteString("digraph tasks {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, n := range g.Nodes {
+		attrs := ""
+		if n.InCycle {
+			attrs = ", color=red"
+		}
+		fmt.Fprintf(&b, "\tt%d [label=\"#%d %s\\n(%s)\"%s];\n", n.ID, n.ID, quote.Replace(n.Title), quote.Replace(n.Status), attrs)
+	}
+	for _, e := range g.Edges {
+		attrs := ""
+		if e.InCycle {
+			attrs = " [color=red]"
+		}
+		fmt.Fprintf(&b, "\tt%d -> t%d%s;\n", e.From, e.To, attrs)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// getTaskGraph returns the dependency graph of the tasks matching the
+// usual filters, as JSON or, with ?format=dot, Graphviz DOT. Edges point
+// from a task to the task it depends on. Graphs over GRAPH_MAX_NODES tasks
+// are refused.
+func getTaskGraph(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "dot" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "format must be json or dot",
+		})
+		return
+	}
+	filter, err := taskFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	maxNodes := envInt("GRAPH_MAX_NODES", 500)
+
+	where, args := filter.where()
+	rows, err := db.QueryContext(c.Request.Context(), "SELECT id, title, status FROM tasks"+where+" ORDER BY id LIMIT ?", append(args, maxNodes+1)...)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+	defer rows.Close()
+
+	graph := taskGraph{Nodes: []graphNode{}, Edges: []graphEdge{}}
+	inGraph := make(map[int]bool)
+	for rows.Next() {
+		var n graphNode
+		if err := rows.Scan(&n.ID, &n.Title, &n.Status); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan task",
+			})
+			return
+		}
+		graph.Nodes = append(graph.Nodes, n)
+		inGraph[n.ID] = true
+	}
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+	if len(graph.Nodes) > maxNodes {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "graph too large; narrow the filter",
+			"limit": maxNodes,
+		})
+		return
+	}
+
+	edgeRows, err := db.QueryContext(c.Request.Context(), "SELECT task_id, depends_on_id FROM task_dependencies ORDER BY task_id, depends_on_id")
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch dependencies",
+		})
+		return
+	}
+	defer edgeRows.Close()
+
+	for edgeRows.Next() {
+		var e graphEdge
+		if err := edgeRows.Scan(&e.From, &e.To); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan dependency",
+			})
+			return
+		}
+		if inGraph[e.From] && inGraph[e.To] {
+			graph.Edges = append(graph.Edges, e)
+		}
+	}
+	if err := edgeRows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch dependencies",
+		})
+		return
+	}
+
+	graph.markCycles()
+
+	if format == "dot" {
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(graph.dot()))
+		return
+	}
+	c.JSON(http.StatusOK, graph)
+}
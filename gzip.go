@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultCompressMinSize = 1024
+
+// gzipWriter buffers the start of a response until it knows whether the body
+// is large enough to be worth compressing. Streaming responses, detected by
+// content type or by the handler flushing, are always sent uncompressed.
+type gzipWriter struct {
+	gin.ResponseWriter
+	minSize int
+	buf     bytes.Buffer
+	decided bool
+	gz      *gzip.Writer
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		return w.writeDecided(data)
+	}
+
+	w.buf.Write(data)
+	if w.buf.Len() >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *gzipWriter) Flush() {
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *gzipWriter) writeDecided(data []byte) (int, error) {
+	if w.gz != nil {
+		return w.gz.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// decide picks compressed or plain output and writes out anything buffered so far.
+func (w *gzipWriter) decide(compress bool) error {
+	w.decided = true
+
+	header := w.Header()
+	if isStreamingContentType(header.Get("Content-Type")) {
+		// A stream is never compressed, so it does not vary by encoding.
+		compress = false
+		dropVary(header, "Accept-Encoding")
+	}
+	if compress && header.Get("Content-Encoding") != "" {
+		compress = false
+	}
+	if compress {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.writeDecided(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+func (w *gzipWriter) finish() {
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+	}
+}
+
+// dropVary removes the Vary entry added for name, leaving any others.
+func dropVary(header http.Header, name string) {
+	values := header.Values("Vary")
+	kept := values[:0]
+	for _, v := range values {
+		if !strings.EqualFold(v, name) {
+			kept = append(kept, v)
+		}
+	}
+	header.Del("Vary")
+	for _, v := range kept {
+		header.Add("Vary", v)
+	}
+}
+
+func isStreamingContentType(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/event-stream")
+}
+
+// gzipMiddleware compresses responses of at least COMPRESS_MIN_SIZE bytes for
+// clients that accept gzip. Any request may opt out with ?no_compress=true.
+func gzipMiddleware() gin.HandlerFunc {
+	minSize := envInt("COMPRESS_MIN_SIZE", defaultCompressMinSize)
+
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead ||
+			c.Query("no_compress") == "true" ||
+			!strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") ||
+			isStreamingContentType(c.GetHeader("Accept")) {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		w := &gzipWriter{ResponseWriter: c.Writer, minSize: minSize}
+		c.Writer = w
+		defer func() {
+			w.finish()
+			c.Writer = w.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGzipThreshold(t *testing.T) {
+	router := newTestServer(t, "COMPRESS_MIN_SIZE=200")
+	for i := 0; i < 5; i++ {
+		createTestTask(t, router, fmt.Sprintf(`{"title":"task %d"}`, i))
+	}
+
+	w := doRequest(router, http.MethodGet, "/ping", "", "Accept-Encoding: gzip")
+	expectStatus(t, w, http.StatusOK)
+	if enc := w.Header().Get("Content-Encoding"); enc != "" {
+		t.Errorf("small response Content-Encoding = %q, want none", enc)
+	}
+
+	w = doRequest(router, http.MethodGet, "/tasks", "", "Accept-Encoding: gzip")
+	expectStatus(t, w, http.StatusOK)
+	if enc := w.Header().Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("large response Content-Encoding = %q, want gzip", enc)
+	}
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tasks []Task
+	if err := json.NewDecoder(gz).Decode(&tasks); err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 5 {
+		t.Fatalf("decompressed %d tasks, want 5", len(tasks))
+	}
+
+	w = doRequest(router, http.MethodGet, "/tasks?no_compress=true", "", "Accept-Encoding: gzip")
+	expectStatus(t, w, http.StatusOK)
+	if enc := w.Header().Get("Content-Encoding"); enc != "" {
+		t.Errorf("opted-out response Content-Encoding = %q, want none", enc)
+	}
+}
+
+func TestGzipSkipsEventStream(t *testing.T) {
+	router := newTestServer(t, "COMPRESS_MIN_SIZE=1", "EVENTS_POLL_INTERVAL=10ms")
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/tasks/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("stream status = %d, want 200", resp.StatusCode)
+	}
+	if enc := resp.Header.Get("Content-Encoding"); enc != "" {
+		t.Errorf("stream Content-Encoding = %q, want none", enc)
+	}
+	if vary := resp.Header.Values("Vary"); len(vary) != 0 {
+		t.Errorf("stream Vary = %q, want none", vary)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tasks are moving from integer ids to UUIDs. During the transition every
+// task carries both: the integer "id" and a UUID "public_id". Routes taking
+// :id accept either form and responses include both.
+//
+// The deprecation plan for the integer id is:
+//  1. Now: both identifiers resolve; clients should store public_id.
+//  2. Next release: requests that address a task by integer id get a
+//     Deprecation header.
+//  3. The release after: integer ids are dropped from routes and responses.
+//     They remain the internal primary key.
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// newPublicID returns a random (version 4) UUID.
+func newPublicID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// backfillPublicIDs gives a public_id to tasks created before it existed.
+func backfillPublicIDs() error {
+	rows, err := db.Query("SELECT id FROM tasks WHERE public_id IS NULL")
+	if err != nil {
+		return err
+	}
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if _, err := db.Exec("UPDATE tasks SET public_id = ? WHERE id = ?", newPublicID(), id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errInvalidTaskID is returned by resolveTaskID for a value that is neither
+// an integer id nor a UUID.
+var errInvalidTaskID = errors.New("invalid task ID")
+
+// resolveTaskID turns an integer id or a public_id UUID, in any case, into
+// the integer id. An unknown UUID gives sql.ErrNoRows; an unknown integer id
+// is returned as is for the caller to look up.
+func resolveTaskID(q queryer, raw string) (int, error) {
+	if id, err := strconv.Atoi(raw); err == nil {
+		return id, nil
+	}
+	if !uuidPattern.MatchString(raw) {
+		return 0, errInvalidTaskID
+	}
+
+	var id int
+	err := q.QueryRow("SELECT id FROM tasks WHERE public_id = ?", strings.ToLower(raw)).Scan(&id)
+	return id, err
+}
+
+// lookupTaskID resolves raw like resolveTaskID. On failure it writes the
+// error response and returns false.
+func lookupTaskID(c *gin.Context, raw string) (int, bool) {
+	id, err := resolveTaskID(requestDB(c), raw)
+	switch {
+	case err == nil:
+		return id, true
+	case err == errInvalidTaskID:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task ID",
+		})
+	case err == sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "task not found",
+		})
+	case respondTimedOut(c, err):
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch task",
+		})
+	}
+	return 0, false
+}
+
+// taskIDParam resolves the :id route parameter, given either as an integer
+// id or a public_id UUID, to the integer id. On failure it writes the error
+// response and returns false. Soft-deleted tasks still resolve so that
+// handlers such as restore can find them.
+func taskIDParam(c *gin.Context) (int, bool) {
+	return lookupTaskID(c, c.Param("id"))
+}
+
+// taskRef is a task named in a request body, as an integer id or a
+// public_id UUID string. Resolve it with resolveTaskID.
+type taskRef string
+
+func (r *taskRef) UnmarshalJSON(data []byte) error {
+	var id int
+	if err := json.Unmarshal(data, &id); err == nil {
+		*r = taskRef(strconv.Itoa(id))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errInvalidTaskID
+	}
+	*r = taskRef(s)
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTaskRoutesAcceptEitherID(t *testing.T) {
+	router := newTestServer(t)
+	task := createTestTask(t, router, `{"title":"dual"}`)
+	if !uuidPattern.MatchString(task.PublicID) || task.PublicID != strings.ToLower(task.PublicID) {
+		t.Fatalf("public_id = %q, want a lowercase UUID", task.PublicID)
+	}
+
+	for _, id := range []string{fmt.Sprint(task.ID), task.PublicID, strings.ToUpper(task.PublicID)} {
+		w := doRequest(router, http.MethodGet, "/task/"+id, "")
+		expectStatus(t, w, http.StatusOK)
+		var got Task
+		decodeBody(t, w, &got)
+		if got.ID != task.ID || got.PublicID != task.PublicID {
+			t.Errorf("GET /task/%s = %d/%s, want both ids of the task", id, got.ID, got.PublicID)
+		}
+	}
+
+	w := doRequest(router, http.MethodPatch, "/task/"+strings.ToUpper(task.PublicID), `{"status":"in_progress"}`)
+	expectStatus(t, w, http.StatusOK)
+
+	w = doRequest(router, http.MethodGet, "/task/00000000-0000-4000-8000-000000000000", "")
+	expectStatus(t, w, http.StatusNotFound)
+	w = doRequest(router, http.MethodGet, "/task/not-an-id", "")
+	expectStatus(t, w, http.StatusBadRequest)
+}
+
+func TestTaskRefInRequestBodies(t *testing.T) {
+	router := newTestServer(t)
+	target := createTestTask(t, router, `{"title":"target"}`)
+	source := createTestTask(t, router, `{"title":"source"}`)
+
+	body := fmt.Sprintf(`{"source":%q,"target":%d}`, strings.ToUpper(source.PublicID), target.ID)
+	w := doRequest(router, http.MethodPost, "/tasks/merge", body)
+	expectStatus(t, w, http.StatusOK)
+
+	w = doRequest(router, http.MethodPost, "/tasks/merge", `{"source":true,"target":1}`)
+	expectStatus(t, w, http.StatusBadRequest)
+	var resp struct {
+		Error string `json:"error"`
+	}
+	decodeBody(t, w, &resp)
+	if resp.Error != "invalid task ID" {
+		t.Errorf("error = %q, want invalid task ID", resp.Error)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trelloBoard is the subset of a Trello board JSON export that the importer
+// reads.
+type trelloBoard struct {
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		IDList string `json:"idList"`
+		Closed bool   `json:"closed"`
+	} `json:"cards"`
+	Checklists []struct {
+		IDCard     string `json:"idCard"`
+		CheckItems []struct {
+			Name  string `json:"name"`
+			State string `json:"state"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+}
+
+type unmappedCard struct {
+	Card string `json:"card"`
+	List string `json:"list"`
+}
+
+// trelloStatuses is loaded from TRELLO_STATUS_MAP at startup.
+var trelloStatuses map[string]string
+
+// trelloStatusMap reads TRELLO_STATUS_MAP, a comma-separated list of
+// "List name=status" pairs. List names are matched case-insensitively.
+func trelloStatusMap(wf *Workflow) (map[string]string, error) {
+	mapping := make(map[string]string)
+	raw := os.Getenv("TRELLO_STATUS_MAP")
+	if raw == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(raw, ",") {
+		list, status, ok := strings.Cut(pair, "=")
+		list, status = strings.TrimSpace(list), strings.TrimSpace(status)
+		if !ok || list == "" {
+			return nil, fmt.Errorf("invalid TRELLO_STATUS_MAP entry %q", pair)
+		}
+		if !wf.IsStatus(status) {
+			return nil, fmt.Errorf("TRELLO_STATUS_MAP maps %q to unknown status %q", list, status)
+		}
+		mapping[strings.ToLower(list)] = status
+	}
+	return mapping, nil
+}
+
+func initTrelloStatusMap() error {
+	mapping, err := trelloStatusMap(currentWorkflow())
+	if err != nil {
+		return err
+	}
+	trelloStatuses = mapping
+	return nil
+}
+
+// trelloListStatus picks the status for cards in a list: the configured
+// mapping first, then a workflow status whose name matches the list name
+// ignoring case, spaces, dashes and underscores ("To Do" matches todo).
+// Mapped statuses dropped by a workflow reload fall back to name matching.
+func trelloListStatus(wf *Workflow, mapping map[string]string, listName string) (string, bool) {
+	key := strings.ToLower(strings.TrimSpace(listName))
+	if status, ok := mapping[key]; ok && wf.IsStatus(status) {
+		return status, true
+	}
+	squashed := squashName(key)
+	for _, status := range wf.Statuses {
+		if squashName(status) == squashed {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+func squashName(s string) string {
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(s))
+}
+
+// importTrello creates tasks from a Trello board export. Each open card
+// becomes a task with its status taken from its list, and each checklist
+// item becomes a subtask. Cards in lists with no status mapping are not
+// imported and are reported back instead.
+func importTrello(c *gin.Context) {
+	var board trelloBoard
+	if err := bindJSON(c, &board); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	wf := currentWorkflow()
+
+	listNames := make(map[string]string, len(board.Lists))
+	for _, l := range board.Lists {
+		listNames[l.ID] = l.Name
+	}
+
+	type checkItem struct {
+		name string
+		done bool
+	}
+	itemsByCard := make(map[string][]checkItem)
+	for _, cl := range board.Checklists {
+		for _, item := range cl.CheckItems {
+			itemsByCard[cl.IDCard] = append(itemsByCard[cl.IDCard], checkItem{item.Name, item.State == "complete"})
+		}
+	}
+
+	doneStatus := wf.Initial
+	if len(wf.Done) > 0 {
+		doneStatus = wf.Done[0]
+	}
+
+	tx, err := db.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	actor := requestActor(c)
+	var (
+		taskIDs  = []int{}
+		subtasks int
+		unmapped = []unmappedCard{}
+	)
+	for _, card := range board.Cards {
+		if card.Closed || strings.TrimSpace(card.Name) == "" {
+			continue
+		}
+
+		listName := listNames[card.IDList]
+		status, ok := trelloListStatus(wf, trelloStatuses, listName)
+		if !ok {
+			unmapped = append(unmapped, unmappedCard{Card: card.Name, List: listName})
+			continue
+		}
+
+		task := Task{Title: normalizeTitle(card.Name), Status: status, Owner: actor, Tags: []string{}}
+		if err := insertTask(tx, &task, actor); err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to import card",
+			})
+			return
+		}
+		taskIDs = append(taskIDs, task.ID)
+
+		for _, item := range itemsByCard[card.ID] {
+			if strings.TrimSpace(item.name) == "" {
+				continue
+			}
+			sub := Task{Title: normalizeTitle(item.name), Status: wf.Initial, Owner: actor, ParentID: &task.ID, Tags: []string{}}
+			if item.done {
+				sub.Status = doneStatus
+			}
+			if err := insertTask(tx, &sub, actor); err != nil {
+				if respondTimedOut(c, err) {
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "failed to import checklist item",
+				})
+				return
+			}
+			subtasks++
+		}
+	}
+
+	if ownerTaskQuota.Limit > 0 {
+		owned, err := ownedTaskCount(tx, actor)
+		if err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to count owned tasks",
+			})
+			return
+		}
+		if !ownerTaskQuota.check(c, owned, http.StatusForbidden, "task quota exceeded") {
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to commit transaction",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"imported": len(taskIDs),
+		"subtasks": subtasks,
+		"task_ids": taskIDs,
+		"unmapped": unmapped,
+	})
+}
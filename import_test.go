@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+const sampleTrelloBoard = `{
+	"lists": [
+		{"id": "l1", "name": "To Do"},
+		{"id": "l2", "name": "Doing"},
+		{"id": "l3", "name": "Ideas"}
+	],
+	"cards": [
+		{"id": "c1", "name": "write docs", "idList": "l1"},
+		{"id": "c2", "name": "ship it", "idList": "l2"},
+		{"id": "c3", "name": "someday", "idList": "l3"},
+		{"id": "c4", "name": "archived", "idList": "l1", "closed": true}
+	],
+	"checklists": [
+		{"idCard": "c2", "checkItems": [
+			{"name": "tag release", "state": "complete"},
+			{"name": "announce", "state": "incomplete"}
+		]}
+	]
+}`
+
+func TestImportTrello(t *testing.T) {
+	router := newTestServer(t, "TRELLO_STATUS_MAP=Doing=in_progress")
+
+	w := doRequest(router, http.MethodPost, "/tasks/import/trello", sampleTrelloBoard)
+	expectStatus(t, w, http.StatusCreated)
+	var resp struct {
+		Imported int            `json:"imported"`
+		Subtasks int            `json:"subtasks"`
+		TaskIDs  []int          `json:"task_ids"`
+		Unmapped []unmappedCard `json:"unmapped"`
+	}
+	decodeBody(t, w, &resp)
+	if resp.Imported != 2 || resp.Subtasks != 2 {
+		t.Fatalf("response = %+v, want 2 tasks and 2 subtasks", resp)
+	}
+	if len(resp.Unmapped) != 1 || resp.Unmapped[0] != (unmappedCard{Card: "someday", List: "Ideas"}) {
+		t.Errorf("unmapped = %+v, want the Ideas card", resp.Unmapped)
+	}
+
+	if got := getTestTask(t, router, resp.TaskIDs[0]); got.Title != "write docs" || got.Status != "todo" {
+		t.Errorf("first card = %q/%q, want write docs/todo", got.Title, got.Status)
+	}
+	shipped := resp.TaskIDs[1]
+	if got := getTestTask(t, router, shipped); got.Status != "in_progress" {
+		t.Errorf("mapped card status = %q, want in_progress", got.Status)
+	}
+
+	w = doRequest(router, http.MethodGet, "/tasks", "")
+	expectStatus(t, w, http.StatusOK)
+	var tasks []Task
+	decodeBody(t, w, &tasks)
+	subtasks := map[string]string{}
+	for _, task := range tasks {
+		if task.ParentID != nil && *task.ParentID == shipped {
+			subtasks[task.Title] = task.Status
+		}
+	}
+	if subtasks["tag release"] != "done" || subtasks["announce"] != "todo" {
+		t.Errorf("checklist subtasks = %v, want tag release done and announce todo", subtasks)
+	}
+}
+
+func TestLoadConfigRejectsBadTrelloStatusMap(t *testing.T) {
+	for _, raw := range []string{"Doing=nope", "=todo", "Doing"} {
+		t.Setenv("TRELLO_STATUS_MAP", raw)
+		if err := loadConfig(); err == nil {
+			t.Errorf("loadConfig accepted TRELLO_STATUS_MAP=%q", raw)
+		}
+	}
+}
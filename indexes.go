@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+)
+
+// tableIndex is an index the queries rely on.
+type tableIndex struct {
+	name    string
+	table   string
+	columns []string
+	unique  bool
+}
+
+var expectedIndexes = []tableIndex{
+	{"idx_task_dependencies_depends_on", "task_dependencies", []string{"depends_on_id"}, false},
+	{"idx_task_audit_task_id", "task_audit", []string{"task_id"}, false},
+	{"idx_tasks_parent_id", "tasks", []string{"parent_id"}, false},
+	{"idx_tasks_deleted_at", "tasks", []string{"deleted_at"}, false},
+	{"idx_tasks_completed_at", "tasks", []string{"completed_at"}, false},
+	{"idx_tasks_status_position", "tasks", []string{"status", "position", "id"}, false},
+	{"idx_tasks_public_id", "tasks", []string{"public_id"}, true},
+	{"idx_tasks_normalized_title", "tasks", []string{"normalized_title"}, false},
+}
+
+func (ix tableIndex) createSQL() string {
+	unique := ""
+	if ix.unique {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX IF NOT EXISTS %s ON %s (%s)", unique, ix.name, ix.table, strings.Join(ix.columns, ", "))
+}
+
+type existingIndex struct {
+	unique  bool
+	columns []string
+}
+
+// tableIndexes returns the indexes defined on a table by name.
+func tableIndexes(table string) (map[string]existingIndex, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA index_list(%s)", table))
+	if err != nil {
+		return nil, err
+	}
+	indexes := make(map[string]existingIndex)
+	for rows.Next() {
+		var (
+			seq     int
+			name    string
+			unique  bool
+			origin  string
+			partial bool
+		)
+		if err := rows.Scan(&seq, &name, &unique, &origin, &partial); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		indexes[name] = existingIndex{unique: unique}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for name, ix := range indexes {
+		cols, err := db.Query(fmt.Sprintf("PRAGMA index_info(%s)", name))
+		if err != nil {
+			return nil, err
+		}
+		for cols.Next() {
+			var (
+				seqno  int
+				cid    int
+				column string
+			)
+			if err := cols.Scan(&seqno, &cid, &column); err != nil {
+				cols.Close()
+				return nil, err
+			}
+			ix.columns = append(ix.columns, column)
+		}
+		cols.Close()
+		if err := cols.Err(); err != nil {
+			return nil, err
+		}
+		indexes[name] = ix
+	}
+	return indexes, nil
+}
+
+// indexProblems compares the expected indexes with the database, returning
+// those that are missing or defined differently, with the reason.
+func indexProblems() (map[string]string, error) {
+	problems := make(map[string]string)
+	byTable := make(map[string]map[string]existingIndex)
+	for _, want := range expectedIndexes {
+		existing, ok := byTable[want.table]
+		if !ok {
+			var err error
+			if existing, err = tableIndexes(want.table); err != nil {
+				return nil, err
+			}
+			byTable[want.table] = existing
+		}
+
+		have, ok := existing[want.name]
+		switch {
+		case !ok:
+			problems[want.name] = "missing"
+		case have.unique != want.unique || !slices.Equal(have.columns, want.columns):
+			problems[want.name] = fmt.Sprintf("defined on (%s), expected (%s)",
+				strings.Join(have.columns, ", "), strings.Join(want.columns, ", "))
+		}
+	}
+	return problems, nil
+}
+
+// ensureIndexes creates the expected indexes. With verify it also checks
+// each existing index's columns, rebuilding any that were altered by hand,
+// and logs every index it had to fix.
+func ensureIndexes(verify bool) error {
+	if !verify {
+		for _, ix := range expectedIndexes {
+			if _, err := db.Exec(ix.createSQL()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	problems, err := indexProblems()
+	if err != nil {
+		return err
+	}
+	for _, ix := range expectedIndexes {
+		problem, ok := problems[ix.name]
+		if !ok {
+			continue
+		}
+		if problem != "missing" {
+			if _, err := db.Exec("DROP INDEX IF EXISTS " + ix.name); err != nil {
+				return err
+			}
+		}
+		if _, err := db.Exec(ix.createSQL()); err != nil {
+			return fmt.Errorf("create index %s: %w", ix.name, err)
+		}
+		if problem == "missing" {
+			log.Printf("created missing index %s", ix.name)
+		} else {
+			log.Printf("rebuilt index %s: %s", ix.name, problem)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestInitDBRepairsIndexes(t *testing.T) {
+	newTestServer(t)
+	for _, stmt := range []string{
+		"DROP INDEX idx_tasks_parent_id",
+		"DROP INDEX idx_tasks_deleted_at",
+		"CREATE INDEX idx_tasks_deleted_at ON tasks (title)",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	problems, err := indexProblems()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems["idx_tasks_parent_id"] != "missing" || problems["idx_tasks_deleted_at"] == "" || len(problems) != 2 {
+		t.Fatalf("problems = %v, want the dropped and the altered index", problems)
+	}
+
+	// Restart on the same database.
+	db.Close()
+	if err := initDB(); err != nil {
+		t.Fatal(err)
+	}
+	if problems, err := indexProblems(); err != nil || len(problems) != 0 {
+		t.Errorf("problems after restart = %v, %v; want none", problems, err)
+	}
+}
+
+func TestInitDBWithoutIndexVerification(t *testing.T) {
+	newTestServer(t, "VERIFY_INDEXES=false")
+	if _, err := db.Exec("DROP INDEX idx_tasks_parent_id"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	if err := initDB(); err != nil {
+		t.Fatal(err)
+	}
+	if problems, err := indexProblems(); err != nil || len(problems) != 0 {
+		t.Errorf("problems = %v, %v; missing indexes should still be created", problems, err)
+	}
+}
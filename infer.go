@@ -0,0 +1,199 @@
+package main
+
+import (
+	"database/sql"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// progressStatus is the status inferred for a parent whose subtasks have
+// started but are not all done: the first workflow status that is neither
+// initial nor done, or the initial status when there is none.
+func (w *Workflow) progressStatus() string {
+	for _, s := range w.Statuses {
+		if s != w.Initial && !w.IsDone(s) {
+			return s
+		}
+	}
+	return w.Initial
+}
+
+// inferredStatus derives a task's status from its live subtasks.
+func inferredStatus(q queryer, wf *Workflow, taskID int) (string, error) {
+	statuses, err := subtaskStatuses(q, taskID)
+	if err != nil {
+		return "", err
+	}
+	return inferFromStatuses(wf, statuses), nil
+}
+
+// inferFromStatuses is the status inferred from a set of subtask statuses:
+// the initial status while none has started, the first done status once all
+// are done, and the progress status in between.
+func inferFromStatuses(wf *Workflow, statuses map[int]string) string {
+	started, done := 0, 0
+	for _, status := range statuses {
+		if status != wf.Initial {
+			started++
+		}
+		if wf.IsDone(status) {
+			done++
+		}
+	}
+
+	switch {
+	case started == 0:
+		return wf.Initial
+	case done == len(statuses) && len(wf.Done) > 0:
+		return wf.Done[0]
+	default:
+		return wf.progressStatus()
+	}
+}
+
+// subtaskStatuses returns the status of each live subtask of taskID by id.
+func subtaskStatuses(q queryer, taskID int) (map[int]string, error) {
+	rows, err := q.Query("SELECT id, status FROM tasks WHERE parent_id = ? AND deleted_at IS NULL", taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := make(map[int]string)
+	for rows.Next() {
+		var (
+			id     int
+			status string
+		)
+		if err := rows.Scan(&id, &status); err != nil {
+			return nil, err
+		}
+		statuses[id] = status
+	}
+	return statuses, rows.Err()
+}
+
+// liveSubtaskIDs returns the ids of taskID's live subtasks in order.
+func liveSubtaskIDs(q queryer, taskID int) ([]int, error) {
+	statuses, err := subtaskStatuses(q, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(statuses)), nil
+}
+
+// affectedAncestors lists, nearest first, the ancestors whose inferred
+// status would change if task moved to status. It follows the same rule as
+// refreshInferredStatus without writing anything.
+func affectedAncestors(q queryer, wf *Workflow, task Task, status string) ([]int, error) {
+	var ids []int
+	for task.ParentID != nil && task.DeletedAt == nil {
+		parent, err := fetchTask(q, *task.ParentID)
+		if err == sql.ErrNoRows {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !parent.InferStatus {
+			break
+		}
+
+		statuses, err := subtaskStatuses(q, parent.ID)
+		if err != nil {
+			return nil, err
+		}
+		statuses[task.ID] = status
+		next := inferFromStatuses(wf, statuses)
+		if next == parent.Status {
+			break
+		}
+		ids = append(ids, parent.ID)
+		task, status = parent, next
+	}
+	return ids, nil
+}
+
+// refreshInferredStatus recomputes the status of each given task that has
+// infer_status set, and of their ancestors in turn when a status changes.
+// Inferred changes bypass the workflow's transition rules.
+func refreshInferredStatus(tx *sql.Tx, actor string, taskIDs ...int) error {
+	wf := currentWorkflow()
+	seen := make(map[int]bool)
+	for len(taskIDs) > 0 {
+		id := taskIDs[0]
+		taskIDs = taskIDs[1:]
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		before, err := fetchTask(tx, id)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !before.InferStatus {
+			continue
+		}
+
+		status, err := inferredStatus(tx, wf, id)
+		if err != nil {
+			return err
+		}
+		if status == before.Status {
+			continue
+		}
+
+		now := time.Now()
+		if _, err := tx.Exec("UPDATE tasks SET status = ?, updated_at = ? WHERE id = ?", status, formatDBTime(now), id); err != nil {
+			return err
+		}
+		if err := syncCompletedAt(tx, now, id); err != nil {
+			return err
+		}
+		after := before
+		after.Status = status
+		if err := recordAudit(tx, id, "updated", actor, taskChanges(before, after)); err != nil {
+			return err
+		}
+
+		if before.ParentID != nil {
+			taskIDs = append(taskIDs, *before.ParentID)
+		}
+	}
+	return nil
+}
+
+// refreshParentStatus refreshes the inferred status of the parents of the
+// given tasks, deleted or not, after the tasks were changed.
+func refreshParentStatus(tx *sql.Tx, actor string, taskIDs ...int) error {
+	var parents []int
+	for _, id := range taskIDs {
+		var parentID sql.NullInt64
+		err := tx.QueryRow("SELECT parent_id FROM tasks WHERE id = ?", id).Scan(&parentID)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if parentID.Valid {
+			parents = append(parents, int(parentID.Int64))
+		}
+	}
+	return refreshInferredStatus(tx, actor, parents...)
+}
+
+// respondStatusInferred answers a request that tried to set an inferred
+// status.
+func respondStatusInferred(c *gin.Context, taskIDs ...int) {
+	body := gin.H{"error": "status is inferred from subtasks"}
+	if len(taskIDs) > 0 {
+		body["task_ids"] = taskIDs
+	}
+	c.JSON(http.StatusConflict, body)
+}
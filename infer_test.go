@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestInferredParentStatus(t *testing.T) {
+	router := newTestServer(t)
+	parent := createTestTask(t, router, `{"title":"parent","infer_status":true}`)
+	a := createTestTask(t, router, fmt.Sprintf(`{"title":"a","parent_id":%d}`, parent.ID))
+	b := createTestTask(t, router, fmt.Sprintf(`{"title":"b","parent_id":%d}`, parent.ID))
+
+	steps := []struct {
+		task   Task
+		status string
+		want   string
+	}{
+		{a, "in_progress", "in_progress"},
+		{a, "done", "in_progress"},
+		{b, "done", "done"},
+		{b, "todo", "in_progress"},
+		{a, "todo", "todo"},
+	}
+	for _, step := range steps {
+		w := doRequest(router, http.MethodPatch, taskLocation(step.task.ID), `{"status":"`+step.status+`"}`)
+		expectStatus(t, w, http.StatusOK)
+		if got := getTestTask(t, router, parent.ID); got.Status != step.want {
+			t.Fatalf("after %s -> %s parent status = %q, want %q", step.task.Title, step.status, got.Status, step.want)
+		}
+	}
+
+	w := doRequest(router, http.MethodPatch, taskLocation(parent.ID), `{"status":"done"}`)
+	expectStatus(t, w, http.StatusConflict)
+
+	// Deleting the last open subtask completes the parent.
+	doRequest(router, http.MethodPatch, taskLocation(a.ID), `{"status":"done"}`)
+	doRequest(router, http.MethodDelete, taskLocation(b.ID), "")
+	if got := getTestTask(t, router, parent.ID); got.Status != "done" {
+		t.Errorf("parent status = %q after deleting the open subtask, want done", got.Status)
+	}
+}
+
+func TestInferredStatusAfterMerge(t *testing.T) {
+	router := newTestServer(t)
+	target := createTestTask(t, router, `{"title":"target","infer_status":true}`)
+	createTestTask(t, router, fmt.Sprintf(`{"title":"done sub","status":"done","parent_id":%d}`, target.ID))
+	source := createTestTask(t, router, `{"title":"source"}`)
+	createTestTask(t, router, fmt.Sprintf(`{"title":"open sub","parent_id":%d}`, source.ID))
+	if got := getTestTask(t, router, target.ID); got.Status != "done" {
+		t.Fatalf("target status = %q, want done before the merge", got.Status)
+	}
+
+	w := doRequest(router, http.MethodPost, "/tasks/merge", fmt.Sprintf(`{"source":%d,"target":%d}`, source.ID, target.ID))
+	expectStatus(t, w, http.StatusOK)
+	if got := getTestTask(t, router, target.ID); got.Status != "in_progress" {
+		t.Errorf("target status = %q after gaining an open subtask, want in_progress", got.Status)
+	}
+}
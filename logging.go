@@ -0,0 +1,44 @@
+package main
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const requestStartKey = "request_start"
+
+// markRequestStart records when a request arrived so the log sampler can
+// tell slow requests apart. It must run before the logger middleware.
+func markRequestStart() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(requestStartKey, time.Now())
+		c.Next()
+	}
+}
+
+// requestLogger logs one in every LOG_SAMPLE_RATE successful requests.
+// Error responses (4xx and 5xx) and requests slower than LOG_SLOW_THRESHOLD
+// are always logged. A rate of 1 or less logs everything.
+func requestLogger() gin.HandlerFunc {
+	rate := uint64(max(envInt("LOG_SAMPLE_RATE", 1), 1))
+	slow := envDuration("LOG_SLOW_THRESHOLD", time.Second)
+
+	var seen atomic.Uint64
+	return gin.LoggerWithConfig(gin.LoggerConfig{
+		Skip: func(c *gin.Context) bool {
+			return !shouldLogRequest(c, rate, slow, &seen)
+		},
+	})
+}
+
+func shouldLogRequest(c *gin.Context, rate uint64, slow time.Duration, seen *atomic.Uint64) bool {
+	if rate <= 1 || c.Writer.Status() >= 400 {
+		return true
+	}
+	if start := c.GetTime(requestStartKey); !start.IsZero() && time.Since(start) >= slow {
+		return true
+	}
+	return seen.Add(1)%rate == 0
+}
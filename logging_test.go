@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestLoggerSampling(t *testing.T) {
+	var logs bytes.Buffer
+	defaultWriter := gin.DefaultWriter
+	gin.DefaultWriter = &logs
+	t.Cleanup(func() { gin.DefaultWriter = defaultWriter })
+	router := newTestServer(t, "LOG_SAMPLE_RATE=1000", "LOG_SLOW_THRESHOLD=1h")
+
+	for i := 0; i < 5; i++ {
+		expectStatus(t, doRequest(router, http.MethodGet, "/ping", ""), http.StatusOK)
+	}
+	doRequest(router, http.MethodGet, "/task/999", "")
+	doRequest(router, http.MethodPost, "/task", `{"title":`)
+
+	out := logs.String()
+	if strings.Contains(out, "/ping") {
+		t.Errorf("sampled successful requests were logged:\n%s", out)
+	}
+	for _, want := range []string{"| 404 |", "| 400 |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log is missing the %s error response:\n%s", want, out)
+		}
+	}
+}
+
+func TestRequestLoggerLogsSlowRequests(t *testing.T) {
+	var logs bytes.Buffer
+	defaultWriter := gin.DefaultWriter
+	gin.DefaultWriter = &logs
+	t.Cleanup(func() { gin.DefaultWriter = defaultWriter })
+	router := newTestServer(t, "LOG_SAMPLE_RATE=1000", "LOG_SLOW_THRESHOLD=1ns")
+
+	expectStatus(t, doRequest(router, http.MethodGet, "/ping", ""), http.StatusOK)
+	if !strings.Contains(logs.String(), "/ping") {
+		t.Errorf("slow request was not logged:\n%s", logs.String())
+	}
+}
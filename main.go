@@ -2,9 +2,12 @@ package main
 
 import (
 	"database/sql"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
-	"strconv"
+	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/mattn/go-sqlite3"
@@ -13,14 +16,129 @@ import (
 var db *sql.DB
 
 type Task struct {
-	ID     int    `json:"id"`
-	Title  string `json:"title"`
-	Status string `json:"status"`
+	ID       int        `json:"id"`
+	PublicID string     `json:"public_id"`
+	Title    string     `json:"title"`
+	Status   string     `json:"status"`
+	Priority int        `json:"priority"`
+	Position int        `json:"position"`
+	Assignee string     `json:"assignee"`
+	Owner    string     `json:"owner"`
+	DueDate  *time.Time `json:"due_date"`
+	ParentID *int       `json:"parent_id"`
+	Tags     []string   `json:"tags"`
+
+	// InferStatus makes the status read-only and derived from subtasks.
+	InferStatus bool `json:"infer_status"`
+
+	CreatedAt   *time.Time `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+const (
+	minPriority = 0
+	maxPriority = 5
+)
+
+const taskColumns = "id, public_id, title, status, priority, position, assignee, owner, due_date, parent_id, created_at, updated_at, deleted_at, completed_at, infer_status"
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// queryExecer is satisfied by both *sql.DB and *sql.Tx.
+type queryExecer interface {
+	queryer
+	execer
+}
+
+// scanTask reads the columns listed in taskColumns. Tags are loaded
+// separately with attachTags.
+func scanTask(row rowScanner, task *Task) error {
+	var (
+		dueDate     sql.NullString
+		parentID    sql.NullInt64
+		createdAt   sql.NullString
+		updatedAt   sql.NullString
+		deletedAt   sql.NullString
+		completedAt sql.NullString
+	)
+	err := row.Scan(&task.ID, &task.PublicID, &task.Title, &task.Status, &task.Priority, &task.Position, &task.Assignee, &task.Owner,
+		&dueDate, &parentID, &createdAt, &updatedAt, &deletedAt, &completedAt, &task.InferStatus)
+	if err != nil {
+		return err
+	}
+	task.DueDate = parseDBTime(dueDate)
+	task.CreatedAt = parseDBTime(createdAt)
+	task.UpdatedAt = parseDBTime(updatedAt)
+	task.DeletedAt = parseDBTime(deletedAt)
+	task.CompletedAt = parseDBTime(completedAt)
+	task.ParentID = nil
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		task.ParentID = &id
+	}
+	return nil
+}
+
+// fetchTask loads a single live task, including its tags. Soft-deleted
+// tasks are reported as sql.ErrNoRows.
+func fetchTask(q queryer, taskID int) (Task, error) {
+	var task Task
+	err := scanTask(q.QueryRow("SELECT "+taskColumns+" FROM tasks WHERE id = ? AND deleted_at IS NULL", taskID), &task)
+	if err != nil {
+		return task, err
+	}
+
+	tasks := []Task{task}
+	if err := attachTags(q, tasks); err != nil {
+		return task, err
+	}
+	return tasks[0], nil
 }
 
+func validPriority(p int) bool {
+	return p >= minPriority && p <= maxPriority
+}
+
+// taskColumnMigrations are the columns added to the tasks table after its
+// original id, title and status.
+var taskColumnMigrations = []struct {
+	name       string
+	definition string
+}{
+	{"public_id", "TEXT"},
+	{"priority", "INTEGER NOT NULL DEFAULT 0"},
+	{"position", "INTEGER NOT NULL DEFAULT 0"},
+	{"assignee", "TEXT NOT NULL DEFAULT ''"},
+	{"owner", "TEXT NOT NULL DEFAULT ''"},
+	{"due_date", "TEXT"},
+	{"overdue_handled_due", "TEXT"},
+	{"parent_id", "INTEGER"},
+	{"created_at", "TEXT"},
+	{"updated_at", "TEXT"},
+	{"deleted_at", "TEXT"},
+	{"completed_at", "TEXT"},
+	{"infer_status", "INTEGER NOT NULL DEFAULT 0"},
+	{"normalized_title", "TEXT"},
+}
+
+// dbPath is the SQLite database file.
+var dbPath = "tasks.db"
+
+// dbReadOnly is set when db was opened read-only, as -check does.
+var dbReadOnly bool
+
 func initDB() error {
 	var err error
-	db, err = sql.Open("sqlite3", "tasks.db")
+	db, err = sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return err
 	}
@@ -36,9 +154,104 @@ func initDB() error {
 		return err
 	}
 
+	for _, col := range taskColumnMigrations {
+		if err := addColumnIfMissing("tasks", col.name, col.definition); err != nil {
+			return err
+		}
+	}
+
+	createRelatedTablesSQL := `CREATE TABLE IF NOT EXISTS tags (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE
+	);
+	CREATE TABLE IF NOT EXISTS task_tags (
+		task_id INTEGER NOT NULL,
+		tag_id INTEGER NOT NULL,
+		PRIMARY KEY (task_id, tag_id)
+	);
+	CREATE TABLE IF NOT EXISTS task_audit (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		action TEXT NOT NULL,
+		actor TEXT NOT NULL,
+		detail TEXT,
+		created_at TEXT NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS task_dependencies (
+		task_id INTEGER NOT NULL,
+		depends_on_id INTEGER NOT NULL,
+		PRIMARY KEY (task_id, depends_on_id)
+	);`
+
+	_, err = db.Exec(createRelatedTablesSQL)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureIndexes(envBool("VERIFY_INDEXES", true)); err != nil {
+		return err
+	}
+
+	if err := backfillPublicIDs(); err != nil {
+		return err
+	}
+	if err := backfillNormalizedTitles(); err != nil {
+		return err
+	}
+	if envBool("BACKFILL_TIMESTAMPS", true) {
+		if err := backfillTimestamps(); err != nil {
+			return err
+		}
+	}
+	if err := backfillCompletedAt(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// addColumnIfMissing adds a column to an existing table so databases created
+// by older versions pick up new fields on startup.
+func addColumnIfMissing(table, column, definition string) error {
+	columns, err := tableColumns(table)
+	if err != nil {
+		return err
+	}
+	if columns[column] {
+		return nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// tableColumns returns the names of a table's columns, or an empty set when
+// the table does not exist.
+func tableColumns(table string) (map[string]bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
+
 func ping(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": "pong",
@@ -46,8 +259,25 @@ func ping(c *gin.Context) {
 }
 
 func getTasks(c *gin.Context) {
-	rows, err := db.Query("SELECT id, title, status FROM tasks")
+	filter, err := taskFilterFromQuery(c)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	where, args := filter.where()
+	if c.Query("ids_only") == "true" {
+		writeTaskIDs(c, where, args)
+		return
+	}
+
+	rows, err := db.QueryContext(c.Request.Context(), "SELECT "+taskColumns+" FROM tasks"+where, args...)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "failed to fetch tasks",
 		})
@@ -55,10 +285,10 @@ func getTasks(c *gin.Context) {
 	}
 	defer rows.Close()
 
-	var tasks []Task
+	tasks := []Task{}
 	for rows.Next() {
 		var task Task
-		if err := rows.Scan(&task.ID, &task.Title, &task.Status); err != nil {
+		if err := scanTask(rows, &task); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "failed to scan task",
 			})
@@ -66,29 +296,83 @@ func getTasks(c *gin.Context) {
 		}
 		tasks = append(tasks, task)
 	}
-	c.JSON(http.StatusOK, tasks)
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+
+	if err := attachTags(requestDB(c), tasks); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tags",
+		})
+		return
+	}
+	respondList(c, tasks, listMeta{Total: len(tasks)})
 }
 
-func getTask(c *gin.Context) {
-	taskIDStr := c.Param("id")
-	taskID, err := strconv.Atoi(taskIDStr)
+// writeTaskIDs responds with just the ids of the tasks matching a filter,
+// for clients that feed them into bulk endpoints.
+func writeTaskIDs(c *gin.Context, where string, args []any) {
+	rows, err := db.QueryContext(c.Request.Context(), "SELECT id FROM tasks"+where+" ORDER BY id", args...)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid task ID",
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
 		})
 		return
 	}
+	defer rows.Close()
 
-	var task Task
-	err = db.QueryRow("SELECT id, title, status FROM tasks WHERE id = ?", taskID).Scan(
-		&task.ID, &task.Title, &task.Status,
-	)
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan task",
+			})
+			return
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ids": ids})
+}
+
+func getTask(c *gin.Context) {
+	taskID, ok := taskIDParam(c)
+	if !ok {
+		return
+	}
+
+	task, err := fetchTask(requestDB(c), taskID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "task not found",
 			})
 		} else {
+			if respondTimedOut(c, err) {
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "failed to fetch task",
 			})
@@ -96,89 +380,466 @@ func getTask(c *gin.Context) {
 		return
 	}
 
+	if task.UpdatedAt != nil {
+		c.Header("Last-Modified", task.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
+	if notModified(c, taskETag(task)) {
+		return
+	}
 	c.JSON(http.StatusOK, task)
 }
 
 func createTask(c *gin.Context) {
 	var task Task
-	if err := c.ShouldBindJSON(&task); err != nil {
+	if err := bindTaskJSON(c, &task); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if missing := missingTaskFields(task, false); len(missing) > 0 {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid JSON input",
+			"error":  "missing required fields",
+			"fields": missing,
 		})
 		return
 	}
+	task.Title = normalizeTitle(task.Title)
 
-	result, err := db.Exec("INSERT INTO tasks (title, status) VALUES (?, ?)", task.Title, task.Status)
+	wf := currentWorkflow()
+	if task.Status == "" {
+		task.Status = wf.Initial
+	}
+	applyDefaultRules(&task, nil, time.Now())
+	if !wf.IsStatus(task.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid status",
+			"allowed": wf.Statuses,
+		})
+		return
+	}
+	if !validPriority(task.Priority) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("priority must be between %d and %d", minPriority, maxPriority),
+		})
+		return
+	}
+	task.Tags = normalizeTags(task.Tags)
+	if !tagQuota.check(c, len(task.Tags), http.StatusUnprocessableEntity, "too many tags") {
+		return
+	}
+	task.Owner = requestActor(c)
+
+	tx, err := db.BeginTx(c.Request.Context(), nil)
 	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to create task",
+			"error": "failed to start transaction",
 		})
 		return
 	}
+	defer tx.Rollback()
 
-	taskID, err := result.LastInsertId()
+	if err := validateParent(tx, 0, task.ParentID); err != nil {
+		respondParentError(c, err)
+		return
+	}
+
+	if ownerTaskQuota.Limit > 0 {
+		owned, err := ownedTaskCount(tx, task.Owner)
+		if err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to count owned tasks",
+			})
+			return
+		}
+		if !ownerTaskQuota.check(c, owned+1, http.StatusForbidden, "task quota exceeded") {
+			return
+		}
+	}
+
+	duplicateID, err := findDuplicateTitle(tx, task.Title)
 	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to get task ID",
+			"error": "failed to check for duplicate titles",
 		})
 		return
 	}
+
+	if err := insertTask(tx, &task, requestActor(c)); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to create task",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to commit transaction",
+		})
+		return
+	}
+
+	c.Header("Location", taskLocation(task.ID))
+	if respondMinimal(c) {
+		return
+	}
+	// A create always returns the task, so the preference is met either way.
+	if preferredReturn(c) == returnRepresentation {
+		setPreferenceApplied(c, returnRepresentation)
+	}
+
+	created := createdTask{Task: task}
+	if duplicateID != 0 {
+		created.Warnings = append(created.Warnings, taskWarning{
+			Code:    "duplicate_title",
+			Message: fmt.Sprintf("task %d has a similar title", duplicateID),
+			TaskID:  duplicateID,
+		})
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// insertTask stores a new, already validated task with its tags and records
+// the creation in the audit log. It sets the task's ID and timestamps.
+func insertTask(tx *sql.Tx, task *Task, actor string) error {
+	now := time.Now().UTC().Truncate(time.Second)
+	task.PublicID = newPublicID()
+	task.CreatedAt = &now
+	task.UpdatedAt = &now
+	task.CompletedAt = nil
+	if task.InferStatus {
+		// A new task has no subtasks yet.
+		task.Status = currentWorkflow().Initial
+	}
+	if currentWorkflow().IsDone(task.Status) {
+		task.CompletedAt = &now
+	}
+
+	result, err := tx.Exec(`INSERT INTO tasks (public_id, title, normalized_title, status, priority, position, assignee, owner, due_date, parent_id,
+		infer_status, created_at, updated_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		task.PublicID, task.Title, comparableTitle(task.Title), task.Status, task.Priority, task.Position, task.Assignee, task.Owner, nullableDBTime(task.DueDate), task.ParentID,
+		task.InferStatus, formatDBTime(now), formatDBTime(now), nullableDBTime(task.CompletedAt))
+	if err != nil {
+		return err
+	}
+
+	taskID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
 	task.ID = int(taskID)
 
-	c.JSON(http.StatusCreated, task)
+	if err := setTaskTags(tx, task.ID, task.Tags); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, task.ID, "created", actor, task); err != nil {
+		return err
+	}
+	if task.ParentID != nil {
+		return refreshInferredStatus(tx, actor, *task.ParentID)
+	}
+	return nil
 }
 
 func updateTask(c *gin.Context) {
-	taskIDStr := c.Param("id")
-	taskID, err := strconv.Atoi(taskIDStr)
+	taskID, ok := taskIDParam(c)
+	if !ok {
+		return
+	}
+
+	var task Task
+	if err := bindTaskJSON(c, &task); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if missing := missingTaskFields(task, true); len(missing) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "missing required fields",
+			"fields": missing,
+		})
+		return
+	}
+
+	saveTaskUpdate(c, taskID, func(Task) Task { return task })
+}
+
+// saveTaskUpdate loads the current task, lets edit produce its new state,
+// validates the result like a full update and stores it. It writes the
+// response in every case.
+func saveTaskUpdate(c *gin.Context, taskID int, edit func(current Task) Task) {
+	tx, err := db.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	current, err := fetchTask(tx, taskID)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "task not found",
+			})
+		} else {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to fetch task",
+			})
+		}
+		return
+	}
+
+	task := edit(current)
+	applyDefaultRules(&task, &current, time.Now())
+	if missing := missingTaskFields(task, true); len(missing) > 0 {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid task ID",
+			"error":  "missing required fields",
+			"fields": missing,
 		})
 		return
 	}
+	task.Title = normalizeTitle(task.Title)
 
-	var task Task
-	if err := c.ShouldBindJSON(&task); err != nil {
+	wf := currentWorkflow()
+	if task.InferStatus {
+		if current.InferStatus && task.Status != current.Status {
+			respondStatusInferred(c)
+			return
+		}
+		status, err := inferredStatus(tx, wf, taskID)
+		if err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to infer status",
+			})
+			return
+		}
+		task.Status = status
+	}
+	if !wf.IsStatus(task.Status) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid JSON input",
+			"error":   "invalid status",
+			"allowed": wf.Statuses,
 		})
 		return
 	}
+	if !validPriority(task.Priority) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("priority must be between %d and %d", minPriority, maxPriority),
+		})
+		return
+	}
+	task.ID = taskID
+	task.Tags = normalizeTags(task.Tags)
+	if !tagQuota.check(c, len(task.Tags), http.StatusUnprocessableEntity, "too many tags") {
+		return
+	}
 
-	result, err := db.Exec("UPDATE tasks SET title = ?, status = ? WHERE id = ?", task.Title, task.Status, taskID)
+	if !task.InferStatus && !wf.CanTransition(current.Status, task.Status) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   fmt.Sprintf("cannot move task from %q to %q", current.Status, task.Status),
+			"allowed": wf.Transitions[current.Status],
+		})
+		return
+	}
+
+	if !task.InferStatus && wf.IsDone(task.Status) && !wf.IsDone(current.Status) {
+		open, err := openDependencyIDs(tx, taskID)
+		if err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to check dependencies",
+			})
+			return
+		}
+		if len(open) > 0 {
+			respondBlocked(c, open)
+			return
+		}
+	}
+
+	if err := validateParent(tx, taskID, task.ParentID); err != nil {
+		respondParentError(c, err)
+		return
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(`UPDATE tasks SET title = ?, normalized_title = ?, status = ?, priority = ?, position = ?, assignee = ?, due_date = ?,
+		parent_id = ?, infer_status = ?, updated_at = ? WHERE id = ?`,
+		task.Title, comparableTitle(task.Title), task.Status, task.Priority, task.Position, task.Assignee, nullableDBTime(task.DueDate), task.ParentID,
+		task.InferStatus, formatDBTime(now), taskID)
+	if err == nil {
+		err = syncCompletedAt(tx, now, taskID)
+	}
 	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "failed to update task",
 		})
 		return
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil || rowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "task not found or no changes made",
+	if err := setTaskTags(tx, taskID, task.Tags); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to save tags",
 		})
 		return
 	}
+	if changes := taskChanges(current, task); len(changes) > 0 {
+		if err := recordAudit(tx, taskID, "updated", requestActor(c), changes); err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to record audit entry",
+			})
+			return
+		}
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "task updated successfully",
-	})
+	var parents []int
+	for _, parentID := range []*int{current.ParentID, task.ParentID} {
+		if parentID != nil {
+			parents = append(parents, *parentID)
+		}
+	}
+	if err := refreshInferredStatus(tx, requestActor(c), parents...); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to update parent status",
+		})
+		return
+	}
+
+	updated, err := fetchTask(tx, taskID)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch task",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to commit transaction",
+		})
+		return
+	}
+
+	switch preferredReturn(c) {
+	case returnMinimal:
+		respondMinimal(c)
+	case returnRepresentation:
+		setPreferenceApplied(c, returnRepresentation)
+		c.JSON(http.StatusOK, updated)
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"message": "task updated successfully",
+		})
+	}
 }
 
 func deleteTask(c *gin.Context) {
-	taskIDStr := c.Param("id")
-	taskID, err := strconv.Atoi(taskIDStr)
+	taskID, ok := taskIDParam(c)
+	if !ok {
+		return
+	}
+
+	tx, err := db.BeginTx(c.Request.Context(), nil)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid task ID",
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to start transaction",
 		})
 		return
 	}
+	defer tx.Rollback()
+
+	if raw := c.GetHeader("If-Unmodified-Since"); raw != "" {
+		since, err := http.ParseTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid If-Unmodified-Since header",
+			})
+			return
+		}
 
-	result, err := db.Exec("DELETE FROM tasks WHERE id = ?", taskID)
+		var updatedAt sql.NullString
+		err = tx.QueryRow("SELECT updated_at FROM tasks WHERE id = ? AND deleted_at IS NULL", taskID).Scan(&updatedAt)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "task not found",
+			})
+			return
+		}
+		if err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to fetch task",
+			})
+			return
+		}
+
+		// Tasks without an updated_at have no known modification time, so
+		// the precondition cannot fail for them.
+		if modified := parseDBTime(updatedAt); modified != nil && modified.After(since) {
+			c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+			c.JSON(http.StatusPreconditionFailed, gin.H{
+				"error": "task has been modified since the given time",
+			})
+			return
+		}
+	}
+
+	now := formatDBTime(time.Now())
+	result, err := tx.Exec("UPDATE tasks SET deleted_at = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL",
+		now, now, taskID)
 	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "failed to delete task",
 		})
@@ -190,28 +851,196 @@ func deleteTask(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "task not found or no changes made",
 		})
+		return
+	}
+
+	if err := recordAudit(tx, taskID, "deleted", requestActor(c), nil); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to record audit entry",
+		})
+		return
+	}
+	if err := refreshParentStatus(tx, requestActor(c), taskID); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to update parent status",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to commit transaction",
+		})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "task deleted succesfully",
+		"message": "task deleted successfully",
 	})
 }
 
-func main() {
-	err := initDB()
+// loadConfig reads the settings the handlers depend on, so a bad value
+// stops startup before the database is touched.
+func loadConfig() error {
+	if err := initWorkflow(); err != nil {
+		return fmt.Errorf("failed to load workflow: %w", err)
+	}
+	if err := initFieldAliases(); err != nil {
+		return fmt.Errorf("invalid field aliases: %w", err)
+	}
+	if err := initTitleCase(); err != nil {
+		return fmt.Errorf("invalid title case setting: %w", err)
+	}
+	if err := initDefaultRules(); err != nil {
+		return fmt.Errorf("invalid default rules: %w", err)
+	}
+	if err := initDateParsing(); err != nil {
+		return fmt.Errorf("invalid date parsing setting: %w", err)
+	}
+	if err := initDuplicateCheck(); err != nil {
+		return fmt.Errorf("invalid duplicate title setting: %w", err)
+	}
+	if err := initTrelloStatusMap(); err != nil {
+		return fmt.Errorf("invalid Trello status map: %w", err)
+	}
+	alwaysEnvelope = envBool("ALWAYS_ENVELOPE", false)
+	recoverInputPanics = envBool("RECOVER_INPUT_PANICS", true)
+	normalizeJSONBodies = envBool("NORMALIZE_JSON_BODIES", true)
+	requireJSONContentType = envBool("REQUIRE_JSON_CONTENT_TYPE", false)
+	initRetryAfter()
+	if err := initQuotas(); err != nil {
+		return fmt.Errorf("invalid quota configuration: %w", err)
+	}
+	return nil
+}
+
+// newRouter builds the HTTP handler with its middleware and routes.
+func newRouter() (*gin.Engine, error) {
+	requestIDFormat, err := loadRequestIDFormat()
 	if err != nil {
-		log.Fatalf("failed to initialize database: %v", err)
+		return nil, fmt.Errorf("invalid request id format: %w", err)
+	}
+
+	rateLimitCfg, err := loadRateLimitConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limit configuration: %w", err)
+	}
+
+	cacheControl, err := loadCacheControl()
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache control configuration: %w", err)
 	}
-	defer db.Close()
 
-	router := gin.Default()
+	router := gin.New()
+	router.Use(markRequestStart(), requestID(requestIDFormat), requestLogger(), bodyLogger(), countRequests(), gin.Recovery())
+	router.Use(requestTimeout(envDuration("REQUEST_TIMEOUT", 0), envDuration("REQUEST_TIMEOUT_MAX", 30*time.Second), "/tasks/events"))
+	if rateLimitCfg.Default != nil || len(rateLimitCfg.Routes) > 0 {
+		router.Use(rateLimitMiddleware(rateLimitCfg))
+	}
+	router.Use(securityHeadersMiddleware())
+	if len(cacheControl) > 0 {
+		router.Use(cacheControlMiddleware(cacheControl))
+	}
+	router.Use(gzipMiddleware())
 
 	router.GET("/ping", ping)
+	router.GET("/time", getServerTime)
+	router.GET("/activity", getActivity)
 	router.GET("/tasks", getTasks)
+	router.POST("/tasks/bulk-update", bulkUpdateTasks)
+	router.POST("/tasks/bulk-due", bulkSetDueDates)
+	router.POST("/tasks/bulk-clear", bulkClearTasks)
+	router.GET("/tasks/workload", getWorkload)
+	router.GET("/tasks/overdue-summary", getOverdueSummary)
+	router.GET("/tasks/unassigned", getUnassignedTasks)
+	router.GET("/tasks/completed", getCompletedTasks)
+	router.GET("/tasks/stale", getStaleTasks)
+	router.GET("/tasks/random", getRandomTask)
+	router.GET("/tasks/created", getTasksCreated)
+	router.GET("/tasks/trash", getTrash)
+	router.GET("/tasks/diff", getTaskDiff)
+	router.GET("/tasks/graph", getTaskGraph)
+	router.POST("/tasks/merge", mergeTasks)
+	router.POST("/tasks/bulk-restore", bulkRestoreTasks)
+	router.POST("/tasks/clone", cloneTasks)
+	router.POST("/tasks/import/trello", importTrello)
+	router.GET("/tasks/events", limitStreams(envInt("MAX_STREAM_CONNECTIONS", 100)),
+		streamTaskEvents(envDuration("EVENTS_POLL_INTERVAL", time.Second)))
+	router.GET("/tags", getTags)
+	router.GET("/tags/:tag/stats", getTagStats)
 	router.GET("/task/:id", getTask)
+	// HEAD shares the handler; net/http drops the body.
+	router.HEAD("/task/:id", getTask)
+	router.GET("/task/:id/eta", getTaskETA)
+	router.GET("/task/:id/context", getTaskContext)
+	router.GET("/task/:id/transition-preview", getTransitionPreview)
 	router.POST("/task", createTask)
 	router.PUT("/task/:id", updateTask)
+	router.PATCH("/task/:id", patchTask)
 	router.DELETE("/task/:id", deleteTask)
+	router.POST("/task/:id/restore", restoreTask)
+	router.POST("/task/:id/dependencies", addDependency)
+	router.GET("/task/:id/blocked-by", getBlockedBy)
+	router.GET("/task/:id/blocks", getBlocks)
+	router.DELETE("/task/:id/dependencies/:dependsOn", removeDependency)
 
+	admin := router.Group("/admin", requireAdmin())
+	admin.POST("/reassign-owner", reassignOwner)
+	admin.GET("/db-stats", getDBStats)
+	admin.GET("/preflight", getPreflight)
+	admin.GET("/metrics-snapshot", getMetricsSnapshot)
+	admin.POST("/audit/compact", compactAudit)
+
+	return router, nil
+}
+
+func main() {
+	check := flag.Bool("check", false, "validate the configuration and database, print a report and exit")
+	flag.Parse()
+	if *check {
+		os.Exit(preflightMain())
+	}
+
+	if err := loadConfig(); err != nil {
+		log.Fatal(err)
+	}
+
+	err := initDB()
+	if err != nil {
+		log.Fatalf("failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	overdueCfg, err := loadOverdueConfig()
+	if err != nil {
+		log.Fatalf("invalid overdue configuration: %v", err)
+	}
+	startOverdueJob(overdueCfg)
+
+	backupCfg, err := loadBackupConfig()
+	if err != nil {
+		log.Fatalf("invalid backup configuration: %v", err)
+	}
+	startBackupJob(backupCfg)
+
+	auditRetention, err = loadAuditRetentionConfig()
+	if err != nil {
+		log.Fatalf("invalid audit retention configuration: %v", err)
+	}
+	startAuditCompactionJob(auditRetention)
+
+	router, err := newRouter()
+	if err != nil {
+		log.Fatal(err)
+	}
 	router.Run()
 }
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// newTestServer configures the app from env, given as KEY=value pairs, on a
+// fresh database and returns its router.
+func newTestServer(t *testing.T, env ...string) *gin.Engine {
+	t.Helper()
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		t.Setenv(key, value)
+	}
+
+	dir := t.TempDir()
+	dbPath = filepath.Join(dir, "tasks.db")
+	dbReadOnly = false
+	if err := loadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if err := initDB(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	router, err := newRouter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router
+}
+
+// doRequest sends a request through router. A non-empty body is sent as
+// JSON; headers are "Name: value" strings and override that default.
+func doRequest(router http.Handler, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for _, h := range headers {
+		name, value, _ := strings.Cut(h, ":")
+		req.Header.Set(name, strings.TrimSpace(value))
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decodeBody unmarshals a JSON response into v.
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+}
+
+// expectStatus fails the test unless the response has the given status.
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, status, w.Body.String())
+	}
+}
+
+// createTestTask creates a task from a JSON body and returns it.
+func createTestTask(t *testing.T, router http.Handler, body string, headers ...string) Task {
+	t.Helper()
+	w := doRequest(router, http.MethodPost, "/task", body, headers...)
+	expectStatus(t, w, http.StatusCreated)
+	var task Task
+	decodeBody(t, w, &task)
+	return task
+}
+
+// getTestTask fetches a task by id.
+func getTestTask(t *testing.T, router http.Handler, id int) Task {
+	t.Helper()
+	w := doRequest(router, http.MethodGet, taskLocation(id), "")
+	expectStatus(t, w, http.StatusOK)
+	var task Task
+	decodeBody(t, w, &task)
+	return task
+}
+
+// auditActions lists the audit actions recorded for a task, newest first.
+func auditActions(t *testing.T, router http.Handler, taskID int) []string {
+	t.Helper()
+	// The feed only shows the caller's tasks, so ask as the owner.
+	owner := getTestTask(t, router, taskID).Owner
+	w := doRequest(router, http.MethodGet, "/activity?task_id="+strconv.Itoa(taskID), "", "X-User: "+owner)
+	expectStatus(t, w, http.StatusOK)
+	var entries []activityEntry
+	decodeBody(t, w, &entries)
+	actions := make([]string, len(entries))
+	for i, e := range entries {
+		actions[i] = e.Action
+	}
+	return actions
+}
+
+func TestDeleteTaskIfUnmodifiedSince(t *testing.T) {
+	router := newTestServer(t)
+	task := createTestTask(t, router, `{"title":"a"}`)
+	path := taskLocation(task.ID)
+
+	w := doRequest(router, http.MethodDelete, path, "", "If-Unmodified-Since: yesterday")
+	expectStatus(t, w, http.StatusBadRequest)
+
+	w = doRequest(router, http.MethodDelete, path, "", "If-Unmodified-Since: Sat, 01 Jan 2000 00:00:00 GMT")
+	expectStatus(t, w, http.StatusPreconditionFailed)
+	if w.Header().Get("Last-Modified") == "" {
+		t.Error("412 response has no Last-Modified")
+	}
+
+	later := task.UpdatedAt.Add(time.Hour).Format(http.TimeFormat)
+	w = doRequest(router, http.MethodDelete, path, "", "If-Unmodified-Since: "+later)
+	expectStatus(t, w, http.StatusOK)
+
+	w = doRequest(router, http.MethodDelete, path, "", "If-Unmodified-Since: "+later)
+	expectStatus(t, w, http.StatusNotFound)
+}
+
+func TestGetTasksIDsOnly(t *testing.T) {
+	router := newTestServer(t)
+	var want []int
+	for _, status := range []string{"todo", "done", "todo"} {
+		task := createTestTask(t, router, `{"title":"t","status":"`+status+`"}`)
+		if status == "todo" {
+			want = append(want, task.ID)
+		}
+	}
+
+	w := doRequest(router, http.MethodGet, "/tasks?ids_only=true&status=todo", "")
+	expectStatus(t, w, http.StatusOK)
+	var resp map[string][]int
+	decodeBody(t, w, &resp)
+	if len(resp) != 1 || len(resp["ids"]) != 2 || resp["ids"][0] != want[0] || resp["ids"][1] != want[1] {
+		t.Errorf("response = %v, want {\"ids\": %v}", resp, want)
+	}
+
+	w = doRequest(router, http.MethodGet, "/tasks?ids_only=true&status=blocked", "")
+	expectStatus(t, w, http.StatusOK)
+	if got := strings.TrimSpace(w.Body.String()); got != `{"ids":[]}` {
+		t.Errorf("empty selection = %s, want an empty ids array", got)
+	}
+}
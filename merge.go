@@ -0,0 +1,357 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	mergeTargetWins = "target_wins"
+	mergeSourceWins = "source_wins"
+	mergeFillEmpty  = "fill_empty"
+)
+
+type mergeRequest struct {
+	Source   taskRef `json:"source"`
+	Target   taskRef `json:"target"`
+	Strategy string  `json:"strategy"`
+}
+
+// mergeFields decides the target's fields after a merge. target_wins keeps
+// the target as is, source_wins takes the source's values, and fill_empty
+// only takes source values where the target has none.
+func mergeFields(target, source Task, strategy string) Task {
+	merged := target
+	switch strategy {
+	case mergeSourceWins:
+		merged.Title = source.Title
+		merged.Status = source.Status
+		merged.Priority = source.Priority
+		merged.Assignee = source.Assignee
+		merged.DueDate = source.DueDate
+	case mergeFillEmpty:
+		if merged.Priority == minPriority {
+			merged.Priority = source.Priority
+		}
+		if merged.Assignee == "" {
+			merged.Assignee = source.Assignee
+		}
+		if merged.DueDate == nil {
+			merged.DueDate = source.DueDate
+		}
+	}
+	merged.Tags = normalizeTags(append(append([]string{}, target.Tags...), source.Tags...))
+	return merged
+}
+
+// moveDependencies re-points the source's dependency edges, in both
+// directions, at the target. Edges that would join the target to itself or
+// that it already has are dropped. It returns how many edges the target
+// gained.
+func moveDependencies(tx *sql.Tx, sourceID, targetID int) (int64, error) {
+	var moved int64
+	for _, stmt := range []string{
+		`INSERT OR IGNORE INTO task_dependencies (task_id, depends_on_id)
+			SELECT ?, depends_on_id FROM task_dependencies WHERE task_id = ? AND depends_on_id <> ?`,
+		`INSERT OR IGNORE INTO task_dependencies (task_id, depends_on_id)
+			SELECT task_id, ? FROM task_dependencies WHERE depends_on_id = ? AND task_id <> ?`,
+	} {
+		result, err := tx.Exec(stmt, targetID, sourceID, targetID)
+		if err != nil {
+			return 0, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		moved += n
+	}
+	_, err := tx.Exec("DELETE FROM task_dependencies WHERE task_id = ? OR depends_on_id = ?", sourceID, sourceID)
+	return moved, err
+}
+
+// mergeTasks folds a source task into a target: the target's fields are
+// resolved by the chosen strategy, it gains the source's tags, subtasks and
+// dependencies, and the source is soft-deleted. The caller must be allowed
+// to manage both tasks, and a merge that would finish the target is refused
+// while it has open dependencies.
+func mergeTasks(c *gin.Context) {
+	var req mergeRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if req.Strategy == "" {
+		req.Strategy = mergeTargetWins
+	}
+	switch req.Strategy {
+	case mergeTargetWins, mergeSourceWins, mergeFillEmpty:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("strategy must be %s, %s or %s", mergeTargetWins, mergeSourceWins, mergeFillEmpty),
+		})
+		return
+	}
+	sourceID, ok := lookupTaskID(c, string(req.Source))
+	if !ok {
+		return
+	}
+	targetID, ok := lookupTaskID(c, string(req.Target))
+	if !ok {
+		return
+	}
+	if sourceID == targetID {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "source and target must be different tasks",
+		})
+		return
+	}
+
+	tx, err := db.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	var tasks [2]Task
+	for i, id := range []int{sourceID, targetID} {
+		task, err := fetchTask(tx, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": fmt.Sprintf("task %d not found", id),
+				})
+			} else {
+				if respondTimedOut(c, err) {
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "failed to fetch task",
+				})
+			}
+			return
+		}
+		tasks[i] = task
+	}
+	source, target := tasks[0], tasks[1]
+	actor := requestActor(c)
+	if !canManage(source.Owner, actor) || !canManage(target.Owner, actor) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "task is owned by someone else",
+		})
+		return
+	}
+
+	// Moving the source's subtasks under the target would create a cycle if
+	// the target is itself somewhere below the source.
+	if err := validateParent(tx, source.ID, &target.ID); err != nil {
+		var parentErr *parentError
+		if errors.As(err, &parentErr) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "cannot merge a task into one of its own subtasks",
+			})
+			return
+		}
+		respondParentError(c, err)
+		return
+	}
+
+	merged := mergeFields(target, source, req.Strategy)
+	if target.InferStatus {
+		merged.Status = target.Status
+	}
+	if !tagQuota.check(c, len(merged.Tags), http.StatusUnprocessableEntity, "too many tags") {
+		return
+	}
+	wf := currentWorkflow()
+	if !wf.CanTransition(target.Status, merged.Status) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": fmt.Sprintf("cannot move task from %q to %q", target.Status, merged.Status),
+		})
+		return
+	}
+
+	depsMoved, err := moveDependencies(tx, source.ID, target.ID)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to move dependencies",
+		})
+		return
+	}
+	cycle, err := dependsOnTransitively(tx, target.ID, target.ID)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to check dependencies",
+		})
+		return
+	}
+	if cycle {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "merging would create a dependency cycle",
+		})
+		return
+	}
+	if !target.InferStatus && wf.IsDone(merged.Status) && !wf.IsDone(target.Status) {
+		open, err := openDependencyIDs(tx, target.ID)
+		if err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to check dependencies",
+			})
+			return
+		}
+		if len(open) > 0 {
+			respondBlocked(c, open)
+			return
+		}
+	}
+
+	mergedAt := time.Now()
+	now := formatDBTime(mergedAt)
+	_, err = tx.Exec(`UPDATE tasks SET title = ?, normalized_title = ?, status = ?, priority = ?, assignee = ?, due_date = ?,
+		updated_at = ? WHERE id = ?`,
+		merged.Title, comparableTitle(merged.Title), merged.Status, merged.Priority, merged.Assignee, nullableDBTime(merged.DueDate), now, target.ID)
+	if err == nil {
+		err = syncCompletedAt(tx, mergedAt, target.ID)
+	}
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to update target task",
+		})
+		return
+	}
+	if err := setTaskTags(tx, target.ID, merged.Tags); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to save tags",
+		})
+		return
+	}
+
+	subtaskIDs, err := liveSubtaskIDs(tx, source.ID)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch subtasks",
+		})
+		return
+	}
+	for _, id := range subtaskIDs {
+		_, err := tx.Exec("UPDATE tasks SET parent_id = ?, updated_at = ? WHERE id = ?", target.ID, now, id)
+		if err == nil {
+			err = recordAudit(tx, id, "updated", actor, map[string]fieldChange{
+				"parent_id": {source.ID, target.ID},
+			})
+		}
+		if err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to move subtasks",
+			})
+			return
+		}
+	}
+	moved := len(subtaskIDs)
+
+	if _, err := tx.Exec("UPDATE tasks SET deleted_at = ?, updated_at = ? WHERE id = ?", now, now, source.ID); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to delete source task",
+		})
+		return
+	}
+
+	err = refreshInferredStatus(tx, actor, target.ID)
+	if err == nil {
+		err = refreshParentStatus(tx, actor, source.ID, target.ID)
+	}
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to update parent status",
+		})
+		return
+	}
+	if err := recordAudit(tx, target.ID, "merged", actor, gin.H{
+		"source":         source.ID,
+		"strategy":       req.Strategy,
+		"subtasks_moved": moved,
+		"dependencies":   depsMoved,
+		"changes":        taskChanges(target, merged),
+	}); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to record audit entry",
+		})
+		return
+	}
+	if err := recordAudit(tx, source.ID, "deleted", actor, gin.H{"merged_into": target.ID}); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to record audit entry",
+		})
+		return
+	}
+
+	mergedTask, err := fetchTask(tx, target.ID)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch merged task",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to commit transaction",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"task":           mergedTask,
+		"subtasks_moved": moved,
+	})
+}
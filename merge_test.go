@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestMergeTasks(t *testing.T) {
+	router := newTestServer(t)
+	target := createTestTask(t, router, `{"title":"target","priority":1,"tags":["a"]}`)
+	source := createTestTask(t, router, `{"title":"source","priority":3,"assignee":"bob","tags":["b"]}`)
+	sub := createTestTask(t, router, fmt.Sprintf(`{"title":"sub","parent_id":%d}`, source.ID))
+
+	w := doRequest(router, http.MethodPost, "/tasks/merge", fmt.Sprintf(`{"source":%d,"target":%d,"strategy":"fill_empty"}`, source.ID, target.ID))
+	expectStatus(t, w, http.StatusOK)
+	var resp struct {
+		Task          Task `json:"task"`
+		SubtasksMoved int  `json:"subtasks_moved"`
+	}
+	decodeBody(t, w, &resp)
+	merged := resp.Task
+	if resp.SubtasksMoved != 1 || merged.Title != "target" || merged.Priority != 1 || merged.Assignee != "bob" {
+		t.Fatalf("merge = %+v, want target's title and priority, source's assignee", resp)
+	}
+	if !slices.Equal(merged.Tags, []string{"a", "b"}) {
+		t.Errorf("tags = %v, want both tasks' tags", merged.Tags)
+	}
+
+	if got := getTestTask(t, router, sub.ID); got.ParentID == nil || *got.ParentID != target.ID {
+		t.Errorf("subtask parent = %v, want the target", got.ParentID)
+	}
+	if actions := auditActions(t, router, sub.ID); !slices.Contains(actions, "updated") {
+		t.Errorf("subtask audit = %v, want an updated entry for the move", actions)
+	}
+	if actions := auditActions(t, router, target.ID); !slices.Contains(actions, "merged") {
+		t.Errorf("target audit = %v, want a merged entry", actions)
+	}
+	w = doRequest(router, http.MethodGet, taskLocation(source.ID), "")
+	expectStatus(t, w, http.StatusNotFound)
+}
+
+func TestMergeTasksRejectsBadRequests(t *testing.T) {
+	router := newTestServer(t)
+	parent := createTestTask(t, router, `{"title":"parent"}`)
+	child := createTestTask(t, router, fmt.Sprintf(`{"title":"child","parent_id":%d}`, parent.ID))
+
+	for body, status := range map[string]int{
+		fmt.Sprintf(`{"source":%d,"target":%d,"strategy":"coin_flip"}`, parent.ID, child.ID): http.StatusBadRequest,
+		fmt.Sprintf(`{"source":%d,"target":%d}`, parent.ID, parent.ID):                       http.StatusBadRequest,
+		fmt.Sprintf(`{"source":%d,"target":%d}`, parent.ID, child.ID):                        http.StatusBadRequest,
+		fmt.Sprintf(`{"source":999,"target":%d}`, child.ID):                                  http.StatusNotFound,
+	} {
+		w := doRequest(router, http.MethodPost, "/tasks/merge", body)
+		if w.Code != status {
+			t.Errorf("%s: status = %d, want %d", body, w.Code, status)
+		}
+	}
+}
+
+func TestMergeTasksMovesDependencies(t *testing.T) {
+	router := newTestServer(t)
+	blocker := createTestTask(t, router, `{"title":"blocker"}`)
+	source := createTestTask(t, router, `{"title":"source"}`)
+	target := createTestTask(t, router, `{"title":"target"}`)
+	dependent := createTestTask(t, router, `{"title":"dependent"}`)
+	addTestDependency(t, router, source.ID, blocker.ID)
+	addTestDependency(t, router, target.ID, source.ID)
+	addTestDependency(t, router, dependent.ID, source.ID)
+	addTestDependency(t, router, dependent.ID, target.ID)
+
+	w := doRequest(router, http.MethodPost, "/tasks/merge", fmt.Sprintf(`{"source":%d,"target":%d}`, source.ID, target.ID))
+	expectStatus(t, w, http.StatusOK)
+
+	rows, err := db.Query("SELECT task_id, depends_on_id FROM task_dependencies ORDER BY task_id, depends_on_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var edges [][2]int
+	for rows.Next() {
+		var edge [2]int
+		if err := rows.Scan(&edge[0], &edge[1]); err != nil {
+			t.Fatal(err)
+		}
+		edges = append(edges, edge)
+	}
+	want := [][2]int{{target.ID, blocker.ID}, {dependent.ID, target.ID}}
+	if !slices.Equal(edges, want) {
+		t.Errorf("dependencies = %v, want %v: source edges moved to the target without self-edges or duplicates", edges, want)
+	}
+}
+
+func TestMergeTasksBlockedByOpenDependencies(t *testing.T) {
+	router := newTestServer(t)
+	blocker := createTestTask(t, router, `{"title":"blocker"}`)
+	source := createTestTask(t, router, `{"title":"source","status":"done"}`)
+	target := createTestTask(t, router, `{"title":"target"}`)
+	addTestDependency(t, router, target.ID, blocker.ID)
+
+	w := doRequest(router, http.MethodPost, "/tasks/merge", fmt.Sprintf(`{"source":%d,"target":%d,"strategy":"source_wins"}`, source.ID, target.ID))
+	expectStatus(t, w, http.StatusConflict)
+	var resp struct {
+		TaskIDs []int `json:"task_ids"`
+	}
+	decodeBody(t, w, &resp)
+	if !slices.Equal(resp.TaskIDs, []int{blocker.ID}) {
+		t.Errorf("task_ids = %v, want the open blocker", resp.TaskIDs)
+	}
+	if got := getTestTask(t, router, target.ID); got.Status != "todo" {
+		t.Errorf("target status = %q, want it unchanged", got.Status)
+	}
+	getTestTask(t, router, source.ID)
+}
+
+func TestMergeTasksRequiresOwnership(t *testing.T) {
+	router := newTestServer(t)
+	source := createTestTask(t, router, `{"title":"source"}`, "X-User: alice")
+	target := createTestTask(t, router, `{"title":"target"}`, "X-User: bob")
+	body := fmt.Sprintf(`{"source":%d,"target":%d}`, source.ID, target.ID)
+
+	w := doRequest(router, http.MethodPost, "/tasks/merge", body, "X-User: alice")
+	expectStatus(t, w, http.StatusForbidden)
+	getTestTask(t, router, source.ID)
+
+	if _, err := db.Exec("UPDATE tasks SET owner = 'alice' WHERE id = ?", target.ID); err != nil {
+		t.Fatal(err)
+	}
+	w = doRequest(router, http.MethodPost, "/tasks/merge", body, "X-User: alice")
+	expectStatus(t, w, http.StatusOK)
+}
+
+func TestMergeTasksRejectsDependencyCycle(t *testing.T) {
+	router := newTestServer(t)
+	source := createTestTask(t, router, `{"title":"source"}`)
+	target := createTestTask(t, router, `{"title":"target"}`)
+	middle := createTestTask(t, router, `{"title":"middle"}`)
+	addTestDependency(t, router, middle.ID, source.ID)
+	addTestDependency(t, router, target.ID, middle.ID)
+
+	w := doRequest(router, http.MethodPost, "/tasks/merge", fmt.Sprintf(`{"source":%d,"target":%d}`, source.ID, target.ID))
+	expectStatus(t, w, http.StatusConflict)
+	getTestTask(t, router, source.ID)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestCounts tallies responses by status code since the process started.
+var requestCounts = struct {
+	sync.Mutex
+	started  time.Time
+	byStatus map[int]uint64
+}{started: time.Now(), byStatus: make(map[int]uint64)}
+
+func countRequests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		requestCounts.Lock()
+		requestCounts.byStatus[c.Writer.Status()]++
+		requestCounts.Unlock()
+	}
+}
+
+// getMetricsSnapshot returns a point-in-time view of request counters,
+// connection pool statistics and task counts, for diagnostics without a
+// metrics scraper.
+func getMetricsSnapshot(c *gin.Context) {
+	now := time.Now()
+
+	requestCounts.Lock()
+	var total uint64
+	byStatus := make(map[string]uint64, len(requestCounts.byStatus))
+	for status, n := range requestCounts.byStatus {
+		byStatus[strconv.Itoa(status)] = n
+		total += n
+	}
+	uptime := now.Sub(requestCounts.started)
+	requestCounts.Unlock()
+
+	rows, err := db.QueryContext(c.Request.Context(), "SELECT status, deleted_at IS NOT NULL, COUNT(*) FROM tasks GROUP BY 1, 2")
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count tasks",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var (
+		tasksByStatus = make(map[string]int)
+		tasksTotal    int
+		tasksDeleted  int
+	)
+	for rows.Next() {
+		var (
+			status  string
+			deleted bool
+			count   int
+		)
+		if err := rows.Scan(&status, &deleted, &count); err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to count tasks",
+			})
+			return
+		}
+		if deleted {
+			tasksDeleted += count
+			continue
+		}
+		tasksByStatus[status] += count
+		tasksTotal += count
+	}
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count tasks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"generated_at":   now.UTC().Truncate(time.Second),
+		"uptime_seconds": int64(uptime.Seconds()),
+		"requests": gin.H{
+			"total":     total,
+			"by_status": byStatus,
+		},
+		"database": dbStats(),
+		"tasks": gin.H{
+			"total":     tasksTotal,
+			"by_status": tasksByStatus,
+			"deleted":   tasksDeleted,
+		},
+	})
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetMetricsSnapshot(t *testing.T) {
+	router := newTestServer(t, "ADMIN_TOKEN=secret")
+	requestCounts.Lock()
+	requestCounts.byStatus = make(map[int]uint64)
+	requestCounts.Unlock()
+
+	createTestTask(t, router, `{"title":"a"}`)
+	createTestTask(t, router, `{"title":"b","status":"done"}`)
+	gone := createTestTask(t, router, `{"title":"c"}`)
+	doRequest(router, http.MethodDelete, taskLocation(gone.ID), "")
+	doRequest(router, http.MethodGet, "/task/999", "")
+
+	w := doRequest(router, http.MethodGet, "/admin/metrics-snapshot", "", "Authorization: Bearer secret")
+	expectStatus(t, w, http.StatusOK)
+	var snap struct {
+		Requests struct {
+			Total    uint64            `json:"total"`
+			ByStatus map[string]uint64 `json:"by_status"`
+		} `json:"requests"`
+		Database map[string]any `json:"database"`
+		Tasks    struct {
+			Total    int            `json:"total"`
+			ByStatus map[string]int `json:"by_status"`
+			Deleted  int            `json:"deleted"`
+		} `json:"tasks"`
+	}
+	decodeBody(t, w, &snap)
+
+	if snap.Requests.Total != 5 || snap.Requests.ByStatus["201"] != 3 || snap.Requests.ByStatus["404"] != 1 {
+		t.Errorf("requests = %+v, want 3 creates, a delete and a 404", snap.Requests)
+	}
+	if snap.Tasks.Total != 2 || snap.Tasks.ByStatus["todo"] != 1 || snap.Tasks.ByStatus["done"] != 1 || snap.Tasks.Deleted != 1 {
+		t.Errorf("tasks = %+v, want one todo, one done and one deleted", snap.Tasks)
+	}
+	if _, ok := snap.Database["open_connections"]; !ok {
+		t.Errorf("database = %v, want pool statistics", snap.Database)
+	}
+
+	w = doRequest(router, http.MethodGet, "/admin/metrics-snapshot", "")
+	expectStatus(t, w, http.StatusUnauthorized)
+}
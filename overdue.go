@@ -0,0 +1,208 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	overdueActionTag    = "tag"
+	overdueActionStatus = "status"
+)
+
+// overdueConfig controls the background job that reacts to tasks passing
+// their due date. The job is disabled when Interval is zero.
+type overdueConfig struct {
+	Interval time.Duration
+	Action   string
+	Tag      string
+	Status   string
+}
+
+func loadOverdueConfig() (overdueConfig, error) {
+	cfg := overdueConfig{
+		Interval: envDuration("OVERDUE_INTERVAL", 0),
+		Action:   os.Getenv("OVERDUE_ACTION"),
+		Tag:      os.Getenv("OVERDUE_TAG"),
+		Status:   os.Getenv("OVERDUE_STATUS"),
+	}
+	if cfg.Action == "" {
+		cfg.Action = overdueActionTag
+	}
+	if cfg.Tag == "" {
+		cfg.Tag = "overdue"
+	}
+	if cfg.Status == "" {
+		cfg.Status = "overdue"
+	}
+
+	switch cfg.Action {
+	case overdueActionTag:
+		tags := normalizeTags([]string{cfg.Tag})
+		if len(tags) == 0 {
+			return cfg, fmt.Errorf("OVERDUE_TAG must not be blank")
+		}
+		cfg.Tag = tags[0]
+	case overdueActionStatus:
+		if cfg.Interval > 0 && !currentWorkflow().IsStatus(cfg.Status) {
+			return cfg, fmt.Errorf("OVERDUE_STATUS %q is not a workflow status", cfg.Status)
+		}
+	default:
+		return cfg, fmt.Errorf("OVERDUE_ACTION must be %q or %q", overdueActionTag, overdueActionStatus)
+	}
+
+	return cfg, nil
+}
+
+func startOverdueJob(cfg overdueConfig) {
+	if cfg.Interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			n, err := processOverdueTasks(cfg, time.Now())
+			if err != nil {
+				log.Printf("overdue job failed: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("overdue job handled %d tasks", n)
+			}
+		}
+	}()
+}
+
+type overdueTask struct {
+	id          int
+	status      string
+	dueDate     string
+	inferStatus bool
+}
+
+// overdueCondition matches live tasks due at or before the bound time. Done
+// tasks still match and are left for the caller to skip, since the set of
+// done statuses comes from the workflow.
+const overdueCondition = "deleted_at IS NULL AND due_date IS NOT NULL AND due_date <= ?"
+
+// processOverdueTasks applies the configured action to every unfinished task
+// whose due date has passed. Each task remembers the due date it was handled
+// for, so it is processed once per due date: moving the due date re-arms it.
+func processOverdueTasks(cfg overdueConfig, now time.Time) (int, error) {
+	wf := currentWorkflow()
+
+	rows, err := db.Query(`SELECT id, status, due_date, infer_status FROM tasks
+		WHERE `+overdueCondition+`
+		AND (overdue_handled_due IS NULL OR overdue_handled_due <> due_date)`, formatDBTime(now))
+	if err != nil {
+		return 0, err
+	}
+
+	var pending []overdueTask
+	for rows.Next() {
+		var t overdueTask
+		if err := rows.Scan(&t.id, &t.status, &t.dueDate, &t.inferStatus); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if !wf.IsDone(t.status) {
+			pending = append(pending, t)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	handled := 0
+	for _, t := range pending {
+		ok, err := handleOverdueTask(cfg, wf, t)
+		if err != nil {
+			return handled, fmt.Errorf("task %d: %w", t.id, err)
+		}
+		if ok {
+			handled++
+		}
+	}
+	return handled, nil
+}
+
+// handleOverdueTask applies the overdue action to t in its own transaction.
+// It reports false, changing nothing, if the task was deleted or its status
+// or due date changed since it was read; the next run sees the new values.
+// A task the action cannot apply to, because it infers its status or the
+// workflow forbids the move, is marked handled for this due date without an
+// audit entry and also reports false.
+func handleOverdueTask(cfg overdueConfig, wf *Workflow, t overdueTask) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var (
+		status      string
+		inferStatus bool
+	)
+	err = tx.QueryRow("SELECT status, infer_status FROM tasks WHERE id = ? AND deleted_at IS NULL AND due_date = ?",
+		t.id, t.dueDate).Scan(&status, &inferStatus)
+	if err == sql.ErrNoRows || (err == nil && status != t.status) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	now := formatDBTime(time.Now())
+	detail := map[string]any{"due_date": t.dueDate}
+	applied := true
+	switch cfg.Action {
+	case overdueActionTag:
+		if err := addTaskTag(tx, t.id, cfg.Tag); err != nil {
+			return false, err
+		}
+		if _, err := tx.Exec("UPDATE tasks SET updated_at = ? WHERE id = ?", now, t.id); err != nil {
+			return false, err
+		}
+		detail["tag"] = cfg.Tag
+	case overdueActionStatus:
+		if inferStatus {
+			log.Printf("overdue job: task %d infers its status from subtasks", t.id)
+			applied = false
+		} else if wf.CanTransition(status, cfg.Status) {
+			if _, err := tx.Exec("UPDATE tasks SET status = ?, updated_at = ? WHERE id = ?", cfg.Status, now, t.id); err != nil {
+				return false, err
+			}
+			if err := syncCompletedAt(tx, time.Now(), t.id); err != nil {
+				return false, err
+			}
+			if err := refreshParentStatus(tx, systemActor, t.id); err != nil {
+				return false, err
+			}
+			detail["status"] = fieldChange{status, cfg.Status}
+		} else {
+			log.Printf("overdue job: task %d cannot move from %q to %q", t.id, status, cfg.Status)
+			applied = false
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE tasks SET overdue_handled_due = ? WHERE id = ?", t.dueDate, t.id); err != nil {
+		return false, err
+	}
+	if applied {
+		if err := recordAudit(tx, t.id, "overdue", systemActor, detail); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return applied, nil
+}
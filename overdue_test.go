@@ -0,0 +1,103 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestProcessOverdueTasksMovesStatusOnce(t *testing.T) {
+	router := newTestServer(t)
+	cfg := overdueConfig{Interval: time.Minute, Action: overdueActionStatus, Status: "in_progress"}
+	task := createTestTask(t, router, `{"title":"late","due_date":"2020-01-01T00:00:00Z"}`)
+	createTestTask(t, router, `{"title":"finished","status":"done","due_date":"2020-01-01T00:00:00Z"}`)
+
+	n, err := processOverdueTasks(cfg, time.Now())
+	if err != nil || n != 1 {
+		t.Fatalf("processOverdueTasks = %d, %v; want 1 task handled", n, err)
+	}
+	if got := getTestTask(t, router, task.ID); got.Status != "in_progress" {
+		t.Fatalf("status = %q, want in_progress", got.Status)
+	}
+
+	// Moving it back by hand must stick until the due date changes.
+	doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"status":"todo"}`)
+	if n, err := processOverdueTasks(cfg, time.Now()); err != nil || n != 0 {
+		t.Fatalf("second run = %d, %v; want nothing handled", n, err)
+	}
+}
+
+func TestHandleOverdueTaskSkipsChangedStatus(t *testing.T) {
+	router := newTestServer(t)
+	cfg := overdueConfig{Interval: time.Minute, Action: overdueActionStatus, Status: "in_progress"}
+	task := createTestTask(t, router, `{"title":"late","due_date":"2020-01-01T00:00:00Z"}`)
+
+	// The job read the task as todo, then a client finished it.
+	stale := overdueTask{id: task.ID, status: "todo", dueDate: "2020-01-01T00:00:00Z"}
+	doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"status":"done"}`)
+
+	handled, err := handleOverdueTask(cfg, currentWorkflow(), stale)
+	if err != nil || handled {
+		t.Fatalf("handleOverdueTask = %v, %v; want it skipped", handled, err)
+	}
+	if got := getTestTask(t, router, task.ID); got.Status != "done" {
+		t.Fatalf("status = %q, want the client's done kept", got.Status)
+	}
+	if slices.Contains(auditActions(t, router, task.ID), "overdue") {
+		t.Fatal("skipped task got an overdue audit entry")
+	}
+}
+
+func TestProcessOverdueTasksTags(t *testing.T) {
+	router := newTestServer(t)
+	cfg := overdueConfig{Interval: time.Minute, Action: overdueActionTag, Tag: "late"}
+	task := createTestTask(t, router, `{"title":"late","due_date":"2020-01-01T00:00:00Z"}`)
+
+	if _, err := processOverdueTasks(cfg, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if got := getTestTask(t, router, task.ID); !slices.Contains(got.Tags, "late") {
+		t.Fatalf("tags = %v, want late", got.Tags)
+	}
+}
+
+func TestHandleOverdueTaskSkipsChangedDueDate(t *testing.T) {
+	router := newTestServer(t)
+	cfg := overdueConfig{Interval: time.Minute, Action: overdueActionTag, Tag: "late"}
+	task := createTestTask(t, router, `{"title":"late","due_date":"2020-01-01T00:00:00Z"}`)
+
+	// The job read the old due date, then a client moved it.
+	stale := overdueTask{id: task.ID, status: "todo", dueDate: "2020-01-01T00:00:00Z"}
+	doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"due_date":"2020-02-01T00:00:00Z"}`)
+
+	handled, err := handleOverdueTask(cfg, currentWorkflow(), stale)
+	if err != nil || handled {
+		t.Fatalf("handleOverdueTask = %v, %v; want it skipped", handled, err)
+	}
+	if got := getTestTask(t, router, task.ID); slices.Contains(got.Tags, "late") {
+		t.Fatalf("tags = %v, want the stale read ignored", got.Tags)
+	}
+
+	// The new due date is still pending, so the next run handles it.
+	if n, err := processOverdueTasks(cfg, time.Now()); err != nil || n != 1 {
+		t.Fatalf("next run = %d, %v; want the new due date handled", n, err)
+	}
+}
+
+func TestProcessOverdueTasksSkipsWithoutAudit(t *testing.T) {
+	router := newTestServer(t, "WORKFLOW_JSON="+reviewWorkflow)
+	// The workflow has no backlog to shipped move.
+	cfg := overdueConfig{Interval: time.Minute, Action: overdueActionStatus, Status: "shipped"}
+	task := createTestTask(t, router, `{"title":"late","due_date":"2020-01-01T00:00:00Z"}`)
+
+	if n, err := processOverdueTasks(cfg, time.Now()); err != nil || n != 0 {
+		t.Fatalf("processOverdueTasks = %d, %v; want nothing applied", n, err)
+	}
+	if got := getTestTask(t, router, task.ID); got.Status != "backlog" {
+		t.Fatalf("status = %q, want backlog", got.Status)
+	}
+	if slices.Contains(auditActions(t, router, task.ID), "overdue") {
+		t.Fatal("skipped task got an overdue audit entry")
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+type pagination struct {
+	Limit  int
+	Offset int
+}
+
+// parsePagination reads ?limit= and ?offset=, applying the default and
+// maximum page size.
+func parsePagination(c *gin.Context) (pagination, error) {
+	p := pagination{Limit: defaultPageLimit}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return p, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		p.Limit = limit
+	}
+	if raw := c.Query("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return p, fmt.Errorf("offset must be a non-negative integer")
+		}
+		p.Offset = offset
+	}
+
+	return p, nil
+}
+
+// setTotalCount reports the number of items across all pages.
+func setTotalCount(c *gin.Context, total int) {
+	c.Header("X-Total-Count", strconv.Itoa(total))
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// quickEditFields lists the fields PATCH accepts as query parameters, for
+// one-off edits such as PATCH /task/1?status=done.
+var quickEditFields = map[string]bool{
+	"status":   true,
+	"priority": true,
+	"position": true,
+	"assignee": true,
+}
+
+// readOnlyTaskFields lists the task fields the server maintains. Together
+// with taskFields they tell a task field in a quick edit apart from
+// parameters any route accepts, such as envelope or no_compress.
+var readOnlyTaskFields = map[string]bool{
+	"id":           true,
+	"public_id":    true,
+	"owner":        true,
+	"created_at":   true,
+	"updated_at":   true,
+	"completed_at": true,
+	"deleted_at":   true,
+}
+
+// patchTask changes only the fields it is given. They come from a JSON body
+// when there is one, or otherwise from query parameters limited to
+// quickEditFields. Fields that are not client-editable are rejected.
+func patchTask(c *gin.Context) {
+	taskID, ok := taskIDParam(c)
+	if !ok {
+		return
+	}
+
+	body, err := readJSONBody(c)
+	if err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	var edit func(Task) Task
+	if len(bytes.TrimSpace(body)) > 0 {
+		edit = patchFromBody(c, body)
+	} else {
+		edit = patchFromQuery(c)
+	}
+	if edit == nil {
+		return
+	}
+
+	saveTaskUpdate(c, taskID, edit)
+}
+
+// parsePatchBody rewrites aliases and dates in a PATCH body and checks that
+// it decodes as a task, returning the body and its top-level fields.
+func parsePatchBody(c *gin.Context, body []byte) (_ []byte, fields map[string]json.RawMessage, err error) {
+	defer recoverInputPanic(&err)
+	if len(fieldAliases) > 0 {
+		if body, err = rewriteAliases(c, body); err != nil {
+			return nil, nil, err
+		}
+	}
+	if body, err = normalizeDateFields(body); err != nil {
+		return nil, nil, err
+	}
+
+	var probe Task
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return nil, nil, err
+	}
+	return body, fields, nil
+}
+
+func patchFromBody(c *gin.Context, body []byte) func(Task) Task {
+	body, fields, err := parsePatchBody(c, body)
+	if err != nil {
+		respondBindError(c, err)
+		return nil
+	}
+	if protected := nonEditableFields(fields, taskFields); len(protected) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "fields cannot be changed",
+			"fields": protected,
+		})
+		return nil
+	}
+
+	return func(current Task) Task {
+		patched := current
+		// Copy the tags so decoding into the slice cannot alter current.
+		patched.Tags = append([]string(nil), current.Tags...)
+		json.Unmarshal(body, &patched)
+		return patched
+	}
+}
+
+func patchFromQuery(c *gin.Context) func(Task) Task {
+	query := make(url.Values)
+	var protected []string
+	for key, values := range c.Request.URL.Query() {
+		switch {
+		case quickEditFields[key]:
+			query[key] = values
+		case taskFields[key] || readOnlyTaskFields[key]:
+			protected = append(protected, key)
+		}
+	}
+	if len(protected) > 0 {
+		sort.Strings(protected)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "fields cannot be set via query parameters",
+			"fields": protected,
+		})
+		return nil
+	}
+	if len(query) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "request body or query parameters required",
+		})
+		return nil
+	}
+
+	ints := make(map[string]int)
+	for _, key := range []string{"priority", "position"} {
+		if !query.Has(key) {
+			continue
+		}
+		n, err := strconv.Atoi(query.Get(key))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("%s must be an integer", key),
+			})
+			return nil
+		}
+		ints[key] = n
+	}
+
+	return func(current Task) Task {
+		patched := current
+		if query.Has("status") {
+			patched.Status = query.Get("status")
+		}
+		if query.Has("assignee") {
+			patched.Assignee = query.Get("assignee")
+		}
+		if n, ok := ints["priority"]; ok {
+			patched.Priority = n
+		}
+		if n, ok := ints["position"]; ok {
+			patched.Position = n
+		}
+		return patched
+	}
+}
+
+// nonEditableFields returns the sorted keys of fields that allowed does not
+// permit.
+func nonEditableFields[V any](fields map[string]V, allowed map[string]bool) []string {
+	var rejected []string
+	for name := range fields {
+		if !allowed[name] {
+			rejected = append(rejected, name)
+		}
+	}
+	sort.Strings(rejected)
+	return rejected
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPatchTaskBody(t *testing.T) {
+	router := newTestServer(t)
+	task := createTestTask(t, router, `{"title":"body","priority":1,"assignee":"alice"}`)
+
+	w := doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"priority":4}`)
+	expectStatus(t, w, http.StatusOK)
+	got := getTestTask(t, router, task.ID)
+	if got.Priority != 4 || got.Title != "body" || got.Assignee != "alice" {
+		t.Errorf("task = %+v, want only priority changed", got)
+	}
+
+	w = doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"owner":"mallory","id":7}`)
+	expectStatus(t, w, http.StatusBadRequest)
+}
+
+func TestPatchTaskQuickEdit(t *testing.T) {
+	router := newTestServer(t)
+	task := createTestTask(t, router, `{"title":"quick","assignee":"alice"}`)
+
+	w := doRequest(router, http.MethodPatch, taskLocation(task.ID)+"?status=in_progress&priority=3&envelope=true&no_compress=1", "")
+	expectStatus(t, w, http.StatusOK)
+	got := getTestTask(t, router, task.ID)
+	if got.Status != "in_progress" || got.Priority != 3 || got.Assignee != "alice" {
+		t.Errorf("task = %+v, want status and priority changed", got)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"?title=renamed", "fields cannot be set via query parameters"},
+		{"?owner=mallory&status=done", "fields cannot be set via query parameters"},
+		{"?priority=high", "priority must be an integer"},
+		{"?status=nope", "invalid status"},
+		{"?envelope=true", "request body or query parameters required"},
+	}
+	for _, tt := range tests {
+		w := doRequest(router, http.MethodPatch, taskLocation(task.ID)+tt.query, "")
+		expectStatus(t, w, http.StatusBadRequest)
+		if !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s: body = %s, want %q", tt.query, w.Body.String(), tt.want)
+		}
+	}
+	if got := getTestTask(t, router, task.ID); got.Title != "quick" || got.Status != "in_progress" {
+		t.Errorf("task = %+v after rejected quick edits", got)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	returnMinimal        = "minimal"
+	returnRepresentation = "representation"
+)
+
+// preferredReturn reads the RFC 7240 "return" preference from the Prefer
+// header, or returns "" when the client stated none.
+func preferredReturn(c *gin.Context) string {
+	for _, header := range c.Request.Header.Values("Prefer") {
+		for _, pref := range strings.Split(header, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(pref), "=")
+			if strings.EqualFold(strings.TrimSpace(name), "return") {
+				value = strings.ToLower(strings.Trim(strings.TrimSpace(value), `"`))
+				if value == returnMinimal || value == returnRepresentation {
+					return value
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// respondMinimal answers with 204 No Content when the client asked for
+// Prefer: return=minimal, reporting whether it did.
+func respondMinimal(c *gin.Context) bool {
+	if preferredReturn(c) != returnMinimal {
+		return false
+	}
+	setPreferenceApplied(c, returnMinimal)
+	c.Status(http.StatusNoContent)
+	return true
+}
+
+// setPreferenceApplied tells the client which return preference the
+// response honours.
+func setPreferenceApplied(c *gin.Context, value string) {
+	c.Header("Preference-Applied", "return="+value)
+}
+
+func taskLocation(id int) string {
+	return "/task/" + strconv.Itoa(id)
+}
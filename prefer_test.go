@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPreferReturnOnCreate(t *testing.T) {
+	router := newTestServer(t)
+
+	w := doRequest(router, http.MethodPost, "/task", `{"title":"minimal"}`, "Prefer: respond-async, return=minimal")
+	expectStatus(t, w, http.StatusNoContent)
+	if w.Body.Len() != 0 || w.Header().Get("Preference-Applied") != "return=minimal" {
+		t.Errorf("minimal create = %q with Preference-Applied %q", w.Body.String(), w.Header().Get("Preference-Applied"))
+	}
+	location := w.Header().Get("Location")
+	if !strings.HasPrefix(location, "/task/") {
+		t.Fatalf("Location = %q, want the new task", location)
+	}
+	expectStatus(t, doRequest(router, http.MethodGet, location, ""), http.StatusOK)
+
+	w = doRequest(router, http.MethodPost, "/task", `{"title":"full"}`, `Prefer: return="representation"`)
+	expectStatus(t, w, http.StatusCreated)
+	if got := w.Header().Get("Preference-Applied"); got != "return=representation" {
+		t.Errorf("Preference-Applied = %q, want return=representation", got)
+	}
+
+	w = doRequest(router, http.MethodPost, "/task", `{"title":"default"}`)
+	expectStatus(t, w, http.StatusCreated)
+	if got := w.Header().Get("Preference-Applied"); got != "" {
+		t.Errorf("Preference-Applied = %q without a Prefer header", got)
+	}
+}
+
+func TestPreferReturnOnUpdate(t *testing.T) {
+	router := newTestServer(t)
+	task := createTestTask(t, router, `{"title":"a"}`)
+	path := taskLocation(task.ID)
+
+	w := doRequest(router, http.MethodPatch, path, `{"priority":2}`, "Prefer: return=minimal")
+	expectStatus(t, w, http.StatusNoContent)
+
+	w = doRequest(router, http.MethodPut, path, `{"title":"b","status":"done"}`, "Prefer: return=representation")
+	expectStatus(t, w, http.StatusOK)
+	var updated Task
+	decodeBody(t, w, &updated)
+	if updated.Title != "b" || updated.Priority != 0 {
+		t.Errorf("representation = %+v, want the replaced task", updated)
+	}
+
+	w = doRequest(router, http.MethodPatch, path, `{"priority":3}`)
+	expectStatus(t, w, http.StatusOK)
+	if !strings.Contains(w.Body.String(), "task updated successfully") {
+		t.Errorf("default update body = %s, want the message", w.Body.String())
+	}
+}
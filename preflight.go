@@ -0,0 +1,264 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type preflightCheck struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+type preflightReport struct {
+	OK     bool             `json:"ok"`
+	Checks []preflightCheck `json:"checks"`
+}
+
+// Settings read through envInt, envDuration and envBool fall back to their
+// default when invalid, so a typo would otherwise go unnoticed.
+var (
+	intSettings = []string{
+		"MAX_TAGS_PER_TASK", "MAX_TASKS_PER_OWNER", "MAX_STREAM_CONNECTIONS", "BACKUP_KEEP",
+		"COMPRESS_MIN_SIZE", "LOG_SAMPLE_RATE", "GRAPH_MAX_NODES", "AUDIT_KEEP_PER_TASK", "LOG_BODY_MAX_BYTES",
+	}
+	durationSettings = []string{
+		"OVERDUE_INTERVAL", "BACKUP_INTERVAL", "EVENTS_POLL_INTERVAL", "LOG_SLOW_THRESHOLD",
+		"REQUEST_TIMEOUT", "REQUEST_TIMEOUT_MAX", "AUDIT_RETENTION", "AUDIT_COMPACT_INTERVAL",
+		"RETRY_AFTER_STREAMS", "RETRY_AFTER_TIMEOUT", "RETRY_AFTER_UNAVAILABLE", "RETRY_AFTER_RATE_LIMIT",
+	}
+	boolSettings = []string{
+		"SECURITY_HEADERS", "ALWAYS_ENVELOPE", "VERIFY_INDEXES", "LOG_BODIES", "BACKFILL_TIMESTAMPS",
+		"RECOVER_INPUT_PANICS", "NORMALIZE_JSON_BODIES", "REQUIRE_JSON_CONTENT_TYPE",
+	}
+)
+
+// coreTables lists the tables and columns the handlers rely on. The tasks
+// table also needs every column in taskColumnMigrations.
+var coreTables = []struct {
+	name    string
+	columns []string
+}{
+	{"tasks", []string{"id", "title", "status"}},
+	{"tags", []string{"id", "name"}},
+	{"task_tags", []string{"task_id", "tag_id"}},
+	{"task_audit", []string{"id", "task_id", "action", "actor", "detail", "created_at"}},
+	{"task_dependencies", []string{"task_id", "depends_on_id"}},
+}
+
+var preflightChecks = []struct {
+	name string
+	run  func() error
+}{
+	{"workflow", func() error { _, err := loadWorkflow(); return err }},
+	{"default_rules", func() error { _, err := loadDefaultRules(); return err }},
+	{"field_aliases", func() error { _, err := loadFieldAliases(); return err }},
+	{"title_case", func() error { _, err := loadTitleCase(); return err }},
+	{"date_parsing", func() error { _, err := loadDateParsing(); return err }},
+	{"duplicate_title_check", func() error { _, err := loadDuplicateCheck(); return err }},
+	{"tag_quota", func() error { _, err := loadQuota("tags_per_task", "MAX_TAGS_PER_TASK"); return err }},
+	{"owner_task_quota", func() error { _, err := loadQuota("tasks_per_owner", "MAX_TASKS_PER_OWNER"); return err }},
+	{"overdue", func() error { _, err := loadOverdueConfig(); return err }},
+	{"backup", checkBackupConfig},
+	{"audit_retention", func() error { _, err := loadAuditRetentionConfig(); return err }},
+	{"request_id_format", func() error { _, err := loadRequestIDFormat(); return err }},
+	{"rate_limits", func() error { _, err := loadRateLimitConfig(); return err }},
+	{"cache_control", func() error { _, err := loadCacheControl(); return err }},
+	{"trello_status_map", func() error { _, err := trelloStatusMap(currentWorkflow()); return err }},
+	{"env", checkEnvSettings},
+	{"database", checkDatabaseWritable},
+	{"migrations", checkMigrations},
+	{"indexes", checkIndexes},
+}
+
+// runPreflight runs every check. Configuration is re-read from the
+// environment, so the report describes what a restart would load.
+func runPreflight() preflightReport {
+	report := preflightReport{OK: true}
+	for _, check := range preflightChecks {
+		result := preflightCheck{Name: check.name, OK: true}
+		if err := check.run(); err != nil {
+			result.OK = false
+			result.Error = err.Error()
+			report.OK = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+// getPreflight serves the preflight report, with 503 when any check fails.
+func getPreflight(c *gin.Context) {
+	report := runPreflight()
+	status := http.StatusOK
+	if !report.OK {
+		status = http.StatusServiceUnavailable
+		setRetryAfter(c, retryAfter.Unavailable)
+	}
+	c.JSON(status, report)
+}
+
+// preflightMain implements the -check flag: it opens the database
+// read-only, without running migrations or backfills, prints the report on
+// the schema as it is and returns the process exit code.
+func preflightMain() int {
+	w, err := loadWorkflow()
+	if err != nil {
+		w = &defaultWorkflow
+	}
+	workflow.Store(w)
+
+	var openErr error
+	db, openErr = sql.Open("sqlite3", "file:"+dbPath+"?mode=ro")
+	if openErr == nil {
+		defer db.Close()
+		dbReadOnly = true
+		if openErr = db.Ping(); openErr != nil {
+			db.Close()
+			db = nil
+		}
+	}
+
+	report := runPreflight()
+	if openErr != nil {
+		report.OK = false
+		report.Checks = append([]preflightCheck{{Name: "open_database", Error: openErr.Error()}}, report.Checks...)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+
+	if !report.OK {
+		return 1
+	}
+	return 0
+}
+
+func checkBackupConfig() error {
+	cfg, err := loadBackupConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.Dir == "" || cfg.Interval <= 0 {
+		return nil
+	}
+
+	f, err := os.CreateTemp(cfg.Dir, ".preflight-*")
+	if err != nil {
+		return fmt.Errorf("backup directory is not writable: %w", err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+func checkEnvSettings() error {
+	var errs []error
+	for _, key := range intSettings {
+		if raw := os.Getenv(key); raw != "" {
+			if _, err := strconv.Atoi(raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s=%q is not an integer", key, raw))
+			}
+		}
+	}
+	for _, key := range durationSettings {
+		if raw := os.Getenv(key); raw != "" {
+			if _, err := time.ParseDuration(raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s=%q is not a duration", key, raw))
+			}
+		}
+	}
+	for _, key := range boolSettings {
+		if raw := os.Getenv(key); raw != "" {
+			if _, err := strconv.ParseBool(raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s=%q is not a boolean", key, raw))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkDatabaseWritable opens the database file for writing, which changes
+// nothing. On a read-write connection it also creates a table inside a
+// transaction that is then rolled back, which fails on a locked database.
+func checkDatabaseWritable() error {
+	if db == nil {
+		return errors.New("database is not open")
+	}
+
+	f, err := os.OpenFile(dbPath, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("database file is not writable: %w", err)
+	}
+	f.Close()
+	if dbReadOnly {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("CREATE TABLE preflight_write_check (id INTEGER)")
+	return err
+}
+
+func checkMigrations() error {
+	if db == nil {
+		return errors.New("database is not open")
+	}
+
+	var errs []error
+	for _, table := range coreTables {
+		existing, err := tableColumns(table.name)
+		if err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			errs = append(errs, fmt.Errorf("table %s is missing", table.name))
+			continue
+		}
+
+		columns := table.columns
+		if table.name == "tasks" {
+			for _, col := range taskColumnMigrations {
+				columns = append(columns, col.name)
+			}
+		}
+		for _, col := range columns {
+			if !existing[col] {
+				errs = append(errs, fmt.Errorf("column %s.%s is missing", table.name, col))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkIndexes() error {
+	if db == nil {
+		return errors.New("database is not open")
+	}
+
+	problems, err := indexProblems()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, ix := range expectedIndexes {
+		if problem, ok := problems[ix.name]; ok {
+			errs = append(errs, fmt.Errorf("index %s is %s", ix.name, problem))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func checkResults(report preflightReport) map[string]bool {
+	results := make(map[string]bool, len(report.Checks))
+	for _, check := range report.Checks {
+		results[check.Name] = check.OK
+	}
+	return results
+}
+
+func TestGetPreflight(t *testing.T) {
+	router := newTestServer(t, "ADMIN_TOKEN=secret")
+	auth := "Authorization: Bearer secret"
+
+	w := doRequest(router, http.MethodGet, "/admin/preflight", "", auth)
+	expectStatus(t, w, http.StatusOK)
+	var report preflightReport
+	decodeBody(t, w, &report)
+	for name, ok := range checkResults(report) {
+		if !ok {
+			t.Errorf("check %s failed on a healthy server", name)
+		}
+	}
+
+	t.Setenv("LOG_SAMPLE_RATE", "often")
+	t.Setenv("TITLE_CASE", "shouting")
+	w = doRequest(router, http.MethodGet, "/admin/preflight", "", auth)
+	expectStatus(t, w, http.StatusServiceUnavailable)
+	report = preflightReport{}
+	decodeBody(t, w, &report)
+	results := checkResults(report)
+	if report.OK || results["env"] || results["title_case"] || !results["database"] {
+		t.Errorf("report = %+v, want only env and title_case to fail", report)
+	}
+}
+
+func TestPreflightMainLeavesOldSchemaAlone(t *testing.T) {
+	dbPath = filepath.Join(t.TempDir(), "tasks.db")
+	old, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.Exec("CREATE TABLE tasks (id INTEGER PRIMARY KEY, title TEXT, status TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	old.Close()
+	t.Cleanup(func() { dbReadOnly = false })
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	code := preflightMain()
+	os.Stdout = stdout
+	w.Close()
+	io.Copy(io.Discard, r)
+
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1 for missing migrations", code)
+	}
+	check, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer check.Close()
+	var n int
+	if err := check.QueryRow("SELECT COUNT(*) FROM pragma_table_info('tasks') WHERE name = 'public_id'").Scan(&n); err != nil || n != 0 {
+		t.Errorf("public_id columns = %d, %v; -check must not migrate", n, err)
+	}
+}
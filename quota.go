@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	quotaModeHard = "hard"
+	quotaModeSoft = "soft"
+)
+
+// quota caps a count. A hard quota rejects requests that would exceed it; a
+// soft one lets them through with an X-Quota-Warning header so clients can
+// warn users before the limit is enforced. A zero Limit means unlimited.
+type quota struct {
+	Name  string
+	Limit int
+	Soft  bool
+}
+
+var (
+	// tagQuota caps how many distinct tags a client may put on a task.
+	tagQuota quota
+	// ownerTaskQuota caps how many live tasks a single owner may have.
+	ownerTaskQuota quota
+)
+
+// loadQuota reads the limit from limitKey and the mode, hard (default) or
+// soft, from limitKey with a _MODE suffix.
+func loadQuota(name, limitKey string) (quota, error) {
+	q := quota{Name: name, Limit: envInt(limitKey, 0)}
+	switch mode := os.Getenv(limitKey + "_MODE"); mode {
+	case "", quotaModeHard:
+	case quotaModeSoft:
+		q.Soft = true
+	default:
+		return q, fmt.Errorf("%s_MODE must be %q or %q", limitKey, quotaModeHard, quotaModeSoft)
+	}
+	return q, nil
+}
+
+func initQuotas() error {
+	var err error
+	if tagQuota, err = loadQuota("tags_per_task", "MAX_TAGS_PER_TASK"); err != nil {
+		return err
+	}
+	if ownerTaskQuota, err = loadQuota("tasks_per_owner", "MAX_TASKS_PER_OWNER"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// check reports whether a request bringing the count to count may go ahead.
+// Over a soft quota it adds the warning header; over a hard one it writes
+// the rejection with the given status and message.
+func (q quota) check(c *gin.Context, count, status int, message string) bool {
+	if q.Limit <= 0 || count <= q.Limit {
+		return true
+	}
+	if q.Soft {
+		c.Header("X-Quota-Warning", q.Name+"="+strconv.Itoa(count)+"/"+strconv.Itoa(q.Limit))
+		return true
+	}
+
+	c.JSON(status, gin.H{
+		"error": message,
+		"limit": q.Limit,
+		"count": count,
+	})
+	return false
+}
+
+// ownedTaskCount counts the live tasks belonging to owner.
+func ownedTaskCount(q queryer, owner string) (int, error) {
+	var n int
+	err := q.QueryRow("SELECT COUNT(*) FROM tasks WHERE owner = ? AND deleted_at IS NULL", owner).Scan(&n)
+	return n, err
+}
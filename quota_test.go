@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMaxTagsPerTask(t *testing.T) {
+	router := newTestServer(t, "MAX_TAGS_PER_TASK=2")
+
+	// Duplicates collapse after normalisation, so this is exactly at the limit.
+	task := createTestTask(t, router, `{"title":"at limit","tags":["a","A "," a","b"]}`)
+	if len(task.Tags) != 2 {
+		t.Fatalf("tags = %v, want two after dedup", task.Tags)
+	}
+
+	w := doRequest(router, http.MethodPost, "/task", `{"title":"over","tags":["a","b","c","c"]}`)
+	expectStatus(t, w, http.StatusUnprocessableEntity)
+	var resp struct {
+		Error string `json:"error"`
+		Limit int    `json:"limit"`
+		Count int    `json:"count"`
+	}
+	decodeBody(t, w, &resp)
+	if resp.Limit != 2 || resp.Count != 3 {
+		t.Errorf("response = %+v, want limit 2 and count 3", resp)
+	}
+
+	w = doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"tags":["a","b","c"]}`)
+	expectStatus(t, w, http.StatusUnprocessableEntity)
+	if got := getTestTask(t, router, task.ID); len(got.Tags) != 2 {
+		t.Errorf("tags = %v after a rejected update", got.Tags)
+	}
+}
+
+func TestMaxTagsPerTaskUnset(t *testing.T) {
+	router := newTestServer(t, "MAX_TAGS_PER_TASK=")
+	task := createTestTask(t, router, `{"title":"many","tags":["a","b","c","d","e"]}`)
+	if len(task.Tags) != 5 {
+		t.Errorf("tags = %v, want all five", task.Tags)
+	}
+}
+
+func TestSoftAndHardQuotas(t *testing.T) {
+	router := newTestServer(t, "MAX_TAGS_PER_TASK=1", "MAX_TAGS_PER_TASK_MODE=soft", "MAX_TASKS_PER_OWNER=1")
+
+	w := doRequest(router, http.MethodPost, "/task", `{"title":"first","tags":["a","b"]}`, "X-User: alice")
+	expectStatus(t, w, http.StatusCreated)
+	if got := w.Header().Get("X-Quota-Warning"); got != "tags_per_task=2/1" {
+		t.Errorf("X-Quota-Warning = %q, want tags_per_task=2/1", got)
+	}
+
+	w = doRequest(router, http.MethodPost, "/task", `{"title":"second"}`, "X-User: alice")
+	expectStatus(t, w, http.StatusForbidden)
+	w = doRequest(router, http.MethodPost, "/task", `{"title":"bob's first"}`, "X-User: bob")
+	expectStatus(t, w, http.StatusCreated)
+	if got := w.Header().Get("X-Quota-Warning"); got != "" {
+		t.Errorf("X-Quota-Warning = %q under every quota", got)
+	}
+}
+
+func TestLoadQuotaRejectsUnknownMode(t *testing.T) {
+	t.Setenv("MAX_TAGS_PER_TASK_MODE", "strict")
+	if _, err := loadQuota("tags_per_task", "MAX_TAGS_PER_TASK"); err == nil {
+		t.Fatal("loadQuota accepted an unknown mode")
+	}
+}
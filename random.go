@@ -0,0 +1,80 @@
+package main
+
+import (
+	"database/sql"
+	"math/rand/v2"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getRandomTask returns one unfinished task chosen at random, honouring the
+// usual list filters. Rather than ORDER BY RANDOM(), which sorts the whole
+// table, it picks a random id in the matching range and takes the nearest
+// match at or above it, wrapping around to the start when there is none.
+func getRandomTask(c *gin.Context) {
+	filter, err := taskFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	filter.excludeStatuses = currentWorkflow().Done
+
+	where, args := filter.where()
+	var minID, maxID sql.NullInt64
+	err = db.QueryRowContext(c.Request.Context(), "SELECT MIN(id), MAX(id) FROM tasks"+where, args...).Scan(&minID, &maxID)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+	if !minID.Valid {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	pivot := minID.Int64 + rand.Int64N(maxID.Int64-minID.Int64+1)
+	pivotWhere := " WHERE id >= ?"
+	if where != "" {
+		pivotWhere = where + " AND id >= ?"
+	}
+
+	var task Task
+	row := db.QueryRowContext(c.Request.Context(), "SELECT "+taskColumns+" FROM tasks"+pivotWhere+" ORDER BY id LIMIT 1", append(args, pivot)...)
+	err = scanTask(row, &task)
+	if err == sql.ErrNoRows {
+		err = scanTask(db.QueryRowContext(c.Request.Context(), "SELECT "+taskColumns+" FROM tasks"+where+" ORDER BY id LIMIT 1", args...), &task)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.Status(http.StatusNoContent)
+		} else {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to fetch task",
+			})
+		}
+		return
+	}
+
+	tasks := []Task{task}
+	if err := attachTags(requestDB(c), tasks); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tags",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks[0])
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetRandomTask(t *testing.T) {
+	router := newTestServer(t)
+
+	w := doRequest(router, http.MethodGet, "/tasks/random", "")
+	expectStatus(t, w, http.StatusNoContent)
+
+	createTestTask(t, router, `{"title":"finished","status":"done","assignee":"ana"}`)
+	open := createTestTask(t, router, `{"title":"open","assignee":"ana"}`)
+	createTestTask(t, router, `{"title":"other","assignee":"bo"}`)
+
+	for i := 0; i < 20; i++ {
+		w := doRequest(router, http.MethodGet, "/tasks/random?assignee=ana", "")
+		expectStatus(t, w, http.StatusOK)
+		var task Task
+		decodeBody(t, w, &task)
+		if task.ID != open.ID {
+			t.Fatalf("random task = %d (%s), want the only open task for ana", task.ID, task.Title)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimit allows Requests per Window for each client.
+type rateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// parseRateLimit parses limits written as "count/window", e.g. "100/1m" or
+// "5/s". A bare unit is read as one of that unit.
+func parseRateLimit(s string) (rateLimit, error) {
+	count, window, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return rateLimit{}, fmt.Errorf("rate limit %q must look like 100/1m", s)
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 1 {
+		return rateLimit{}, fmt.Errorf("rate limit %q must allow at least one request", s)
+	}
+	if window != "" && !strings.ContainsAny(window[:1], "0123456789") {
+		window = "1" + window
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return rateLimit{}, fmt.Errorf("rate limit %q has an invalid window", s)
+	}
+
+	return rateLimit{Requests: n, Window: d}, nil
+}
+
+// routeLimit applies a limit to routes whose pattern equals Route, or starts
+// with it when Route ends in "*".
+type routeLimit struct {
+	Route string
+	Limit rateLimit
+}
+
+func (r routeLimit) matches(route string) bool {
+	return routeMatches(r.Route, route)
+}
+
+// routeMatches reports whether a gin route pattern matches pattern, which is
+// either a route itself or a prefix ending in "*".
+func routeMatches(pattern, route string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(route, prefix)
+	}
+	return route == pattern
+}
+
+// moreSpecificRoute orders route patterns so exact routes come before
+// prefixes, and longer prefixes before shorter ones.
+func moreSpecificRoute(a, b string) bool {
+	pa, wa := strings.CutSuffix(a, "*")
+	pb, wb := strings.CutSuffix(b, "*")
+	if wa != wb {
+		return !wa
+	}
+	return len(pa) > len(pb)
+}
+
+type rateLimitConfig struct {
+	Default *rateLimit
+	Routes  []routeLimit
+}
+
+// loadRateLimitConfig reads RATE_LIMIT, the limit for routes without their
+// own, and RATE_LIMITS, a comma-separated list of route=limit overrides such
+// as "/tasks/import/*=5/1m". Routes are gin patterns like /task/:id. When
+// neither is set, nothing is limited.
+func loadRateLimitConfig() (rateLimitConfig, error) {
+	var cfg rateLimitConfig
+
+	if raw := os.Getenv("RATE_LIMIT"); raw != "" {
+		limit, err := parseRateLimit(raw)
+		if err != nil {
+			return cfg, fmt.Errorf("RATE_LIMIT: %w", err)
+		}
+		cfg.Default = &limit
+	}
+
+	if raw := os.Getenv("RATE_LIMITS"); raw != "" {
+		for _, pair := range strings.Split(raw, ",") {
+			route, limitStr, ok := strings.Cut(pair, "=")
+			route = strings.TrimSpace(route)
+			if !ok || route == "" {
+				return cfg, fmt.Errorf("RATE_LIMITS: invalid entry %q, expected route=limit", pair)
+			}
+			limit, err := parseRateLimit(limitStr)
+			if err != nil {
+				return cfg, fmt.Errorf("RATE_LIMITS: %w", err)
+			}
+			cfg.Routes = append(cfg.Routes, routeLimit{Route: route, Limit: limit})
+		}
+	}
+
+	sort.SliceStable(cfg.Routes, func(i, j int) bool {
+		return moreSpecificRoute(cfg.Routes[i].Route, cfg.Routes[j].Route)
+	})
+
+	return cfg, nil
+}
+
+// limitFor returns the limit for a route and the bucket group it counts
+// against: the matching override, or the shared default.
+func (cfg rateLimitConfig) limitFor(route string) (rateLimit, string, bool) {
+	for _, r := range cfg.Routes {
+		if r.matches(route) {
+			return r.Limit, r.Route, true
+		}
+	}
+	if cfg.Default != nil {
+		return *cfg.Default, "", true
+	}
+	return rateLimit{}, "", false
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	limit  rateLimit
+}
+
+// take refills the bucket for the time elapsed and consumes one token if
+// available. Otherwise it reports how long until the next token.
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	perToken := b.limit.Window / time.Duration(b.limit.Requests)
+	b.tokens = math.Min(float64(b.limit.Requests), b.tokens+float64(now.Sub(b.last))/float64(perToken))
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) * float64(perToken))
+}
+
+type rateLimiter struct {
+	cfg     rateLimitConfig
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func newRateLimiter(cfg rateLimitConfig) *rateLimiter {
+	return &rateLimiter{cfg: cfg, buckets: make(map[string]*tokenBucket)}
+}
+
+func (l *rateLimiter) allow(route, client string, now time.Time) (bool, time.Duration) {
+	limit, group, ok := l.cfg.limitFor(route)
+	if !ok {
+		return true, 0
+	}
+
+	key := group + "\x00" + client
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(limit.Requests), last: now, limit: limit}
+		l.buckets[key] = b
+	}
+	return b.take(now)
+}
+
+// prune drops buckets idle long enough to have refilled completely, since
+// a fresh bucket behaves the same.
+func (l *rateLimiter) prune(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= b.limit.Window {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimitMiddleware rejects requests over the limit for their route with
+// 429 and a Retry-After header.
+func rateLimitMiddleware(cfg rateLimitConfig) gin.HandlerFunc {
+	limiter := newRateLimiter(cfg)
+
+	go func() {
+		for now := range time.Tick(time.Minute) {
+			limiter.prune(now)
+		}
+	}()
+
+	return func(c *gin.Context) {
+		ok, wait := limiter.allow(c.FullPath(), c.ClientIP(), time.Now())
+		if !ok {
+			setRetryAfter(c, max(wait, retryAfter.RateLimit))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "rate limit exceeded",
+			})
+			return
+		}
+		c.Next()
+	}
+}
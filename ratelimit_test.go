@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	for raw, want := range map[string]rateLimit{
+		"100/1m": {Requests: 100, Window: time.Minute},
+		"5/s":    {Requests: 5, Window: time.Second},
+		" 2/30s": {Requests: 2, Window: 30 * time.Second},
+	} {
+		if got, err := parseRateLimit(raw); err != nil || got != want {
+			t.Errorf("parseRateLimit(%q) = %+v, %v; want %+v", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"100", "0/1m", "x/1m", "5/soon", "5/-1s"} {
+		if _, err := parseRateLimit(raw); err == nil {
+			t.Errorf("parseRateLimit(%q) succeeded", raw)
+		}
+	}
+}
+
+func TestRouteRateLimits(t *testing.T) {
+	router := newTestServer(t, "RATE_LIMIT=5/1m", "RATE_LIMITS=/tasks/import/*=2/1m")
+	board := `{"lists":[],"cards":[]}`
+
+	for i := 0; i < 2; i++ {
+		w := doRequest(router, http.MethodPost, "/tasks/import/trello", board)
+		expectStatus(t, w, http.StatusCreated)
+	}
+	w := doRequest(router, http.MethodPost, "/tasks/import/trello", board)
+	expectStatus(t, w, http.StatusTooManyRequests)
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("429 response has no Retry-After")
+	}
+
+	// The import budget is separate from the default one.
+	for i := 0; i < 5; i++ {
+		w := doRequest(router, http.MethodGet, "/ping", "")
+		expectStatus(t, w, http.StatusOK)
+	}
+	w = doRequest(router, http.MethodGet, "/tasks", "")
+	expectStatus(t, w, http.StatusTooManyRequests)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getTasksCreated lists tasks created since the start of the current day,
+// week or month in the server timezone, oldest first.
+func getTasksCreated(c *gin.Context) {
+	period := c.DefaultQuery("period", "today")
+	since, ok := periodStart(period, time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "period must be one of today, week, month",
+		})
+		return
+	}
+
+	rows, err := db.QueryContext(c.Request.Context(), "SELECT "+taskColumns+" FROM tasks WHERE deleted_at IS NULL AND created_at >= ? ORDER BY created_at, id",
+		formatDBTime(since))
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+	defer rows.Close()
+
+	tasks := []Task{}
+	for rows.Next() {
+		var task Task
+		if err := scanTask(rows, &task); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan task",
+			})
+			return
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+
+	if err := attachTags(requestDB(c), tasks); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tags",
+		})
+		return
+	}
+
+	respondList(c, tasks, listMeta{Total: len(tasks)})
+}
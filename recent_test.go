@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPeriodStart(t *testing.T) {
+	// A Wednesday afternoon in the server timezone.
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.Local)
+	for period, want := range map[string]time.Time{
+		"today": time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local),
+		"week":  time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local),
+		"month": time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local),
+	} {
+		if got, ok := periodStart(period, now); !ok || !got.Equal(want) {
+			t.Errorf("periodStart(%q) = %v, %v; want %v", period, got, ok, want)
+		}
+	}
+	if _, ok := periodStart("year", now); ok {
+		t.Error("periodStart accepted an unknown period")
+	}
+}
+
+func TestGetTasksCreated(t *testing.T) {
+	router := newTestServer(t)
+	old := createTestTask(t, router, `{"title":"old"}`)
+	fresh := createTestTask(t, router, `{"title":"fresh"}`)
+	if _, err := db.Exec("UPDATE tasks SET created_at = ? WHERE id = ?", "2000-01-01T00:00:00Z", old.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	w := doRequest(router, http.MethodGet, "/tasks/created?period=week", "")
+	expectStatus(t, w, http.StatusOK)
+	var tasks []Task
+	decodeBody(t, w, &tasks)
+	if len(tasks) != 1 || tasks[0].ID != fresh.ID {
+		t.Fatalf("created this week = %+v, want only the fresh task", tasks)
+	}
+
+	w = doRequest(router, http.MethodGet, "/tasks/created?period=decade", "")
+	expectStatus(t, w, http.StatusBadRequest)
+}
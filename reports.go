@@ -0,0 +1,172 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type assigneeWorkload struct {
+	Assignee string         `json:"assignee"`
+	Total    int            `json:"total"`
+	Open     int            `json:"open"`
+	ByStatus map[string]int `json:"by_status"`
+}
+
+// getWorkload reports task counts per assignee, broken down by status.
+// With ?open=true only tasks in a non-done status are counted.
+func getWorkload(c *gin.Context) {
+	openOnly := c.Query("open") == "true"
+	wf := currentWorkflow()
+
+	rows, err := db.QueryContext(c.Request.Context(), `SELECT assignee, status, COUNT(*) FROM tasks
+		WHERE deleted_at IS NULL
+		GROUP BY assignee, status`)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch workload",
+		})
+		return
+	}
+	defer rows.Close()
+
+	byAssignee := make(map[string]*assigneeWorkload)
+	for rows.Next() {
+		var (
+			assignee string
+			status   string
+			count    int
+		)
+		if err := rows.Scan(&assignee, &status, &count); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan workload",
+			})
+			return
+		}
+
+		done := wf.IsDone(status)
+		if openOnly && done {
+			continue
+		}
+
+		w, ok := byAssignee[assignee]
+		if !ok {
+			w = &assigneeWorkload{Assignee: assignee, ByStatus: make(map[string]int)}
+			byAssignee[assignee] = w
+		}
+		w.ByStatus[status] = count
+		w.Total += count
+		if !done {
+			w.Open += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch workload",
+		})
+		return
+	}
+
+	workload := make([]assigneeWorkload, 0, len(byAssignee))
+	for _, w := range byAssignee {
+		workload = append(workload, *w)
+	}
+	sort.Slice(workload, func(i, j int) bool {
+		if workload[i].Total != workload[j].Total {
+			return workload[i].Total > workload[j].Total
+		}
+		return workload[i].Assignee < workload[j].Assignee
+	})
+
+	respondList(c, workload, listMeta{Total: len(workload)})
+}
+
+type overdueWorkload struct {
+	Assignee             string    `json:"assignee"`
+	Count                int       `json:"count"`
+	OldestDueDate        time.Time `json:"oldest_due_date"`
+	OldestOverdue        string    `json:"oldest_overdue"`
+	OldestOverdueSeconds int64     `json:"oldest_overdue_seconds"`
+}
+
+// getOverdueSummary reports, per assignee, how many unfinished tasks are
+// past their due date and how long the oldest of them has been overdue.
+// Assignees with the most overdue tasks come first.
+func getOverdueSummary(c *gin.Context) {
+	now := time.Now().UTC().Truncate(time.Second)
+	wf := currentWorkflow()
+
+	rows, err := db.QueryContext(c.Request.Context(), "SELECT assignee, status, due_date FROM tasks WHERE "+overdueCondition, formatDBTime(now))
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch overdue tasks",
+		})
+		return
+	}
+	defer rows.Close()
+
+	byAssignee := make(map[string]*overdueWorkload)
+	for rows.Next() {
+		var (
+			assignee string
+			status   string
+			dueDate  string
+		)
+		if err := rows.Scan(&assignee, &status, &dueDate); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan overdue tasks",
+			})
+			return
+		}
+		due, err := time.Parse(dbTimeLayout, dueDate)
+		if err != nil || wf.IsDone(status) {
+			continue
+		}
+
+		w, ok := byAssignee[assignee]
+		if !ok {
+			w = &overdueWorkload{Assignee: assignee, OldestDueDate: due}
+			byAssignee[assignee] = w
+		}
+		w.Count++
+		if due.Before(w.OldestDueDate) {
+			w.OldestDueDate = due
+		}
+	}
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch overdue tasks",
+		})
+		return
+	}
+
+	summary := make([]overdueWorkload, 0, len(byAssignee))
+	for _, w := range byAssignee {
+		age := now.Sub(w.OldestDueDate)
+		w.OldestOverdue = age.String()
+		w.OldestOverdueSeconds = int64(age.Seconds())
+		summary = append(summary, *w)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Count != summary[j].Count {
+			return summary[i].Count > summary[j].Count
+		}
+		return summary[i].Assignee < summary[j].Assignee
+	})
+
+	respondList(c, summary, listMeta{Total: len(summary)})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetWorkload(t *testing.T) {
+	router := newTestServer(t)
+	createTestTask(t, router, `{"title":"a","assignee":"ana"}`)
+	createTestTask(t, router, `{"title":"b","assignee":"ana","status":"done"}`)
+	createTestTask(t, router, `{"title":"c","assignee":"bo","status":"in_progress"}`)
+
+	w := doRequest(router, http.MethodGet, "/tasks/workload", "")
+	expectStatus(t, w, http.StatusOK)
+	var workload []assigneeWorkload
+	decodeBody(t, w, &workload)
+	if len(workload) != 2 || workload[0].Assignee != "ana" {
+		t.Fatalf("workload = %+v, want ana first of two", workload)
+	}
+	ana := workload[0]
+	if ana.Total != 2 || ana.Open != 1 || ana.ByStatus["done"] != 1 || ana.ByStatus["todo"] != 1 {
+		t.Errorf("ana = %+v, want 2 total, 1 open, one todo and one done", ana)
+	}
+
+	w = doRequest(router, http.MethodGet, "/tasks/workload?open=true", "")
+	expectStatus(t, w, http.StatusOK)
+	workload = nil
+	decodeBody(t, w, &workload)
+	if len(workload) != 2 {
+		t.Fatalf("open workload has %d assignees, want 2", len(workload))
+	}
+	for _, a := range workload {
+		if a.ByStatus["done"] != 0 || a.Total != a.Open {
+			t.Errorf("open=true counted done tasks for %s: %+v", a.Assignee, a)
+		}
+	}
+}
+
+func TestGetOverdueSummary(t *testing.T) {
+	router := newTestServer(t)
+	due := func(d time.Duration) string {
+		return time.Now().UTC().Add(d).Format(time.RFC3339)
+	}
+	for _, body := range []string{
+		`{"title":"a1","assignee":"ana","due_date":"` + due(-72*time.Hour) + `"}`,
+		`{"title":"a2","assignee":"ana","due_date":"` + due(-time.Hour) + `"}`,
+		`{"title":"a3","assignee":"ana","status":"done","due_date":"` + due(-100*time.Hour) + `"}`,
+		`{"title":"a4","assignee":"ana","due_date":"` + due(time.Hour) + `"}`,
+		`{"title":"b1","assignee":"bo","due_date":"` + due(-24*time.Hour) + `"}`,
+	} {
+		createTestTask(t, router, body)
+	}
+
+	w := doRequest(router, http.MethodGet, "/tasks/overdue-summary", "")
+	expectStatus(t, w, http.StatusOK)
+	var summary []overdueWorkload
+	decodeBody(t, w, &summary)
+	if len(summary) != 2 || summary[0].Assignee != "ana" || summary[0].Count != 2 || summary[1].Count != 1 {
+		t.Fatalf("summary = %+v, want ana with 2 then bo with 1", summary)
+	}
+	if age := time.Duration(summary[0].OldestOverdueSeconds) * time.Second; age < 71*time.Hour || age > 73*time.Hour {
+		t.Errorf("ana's oldest overdue = %v, want about 72h", age)
+	}
+}
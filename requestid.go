@@ -0,0 +1,77 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	requestIDKey    = "request_id"
+
+	requestIDFormatUUID   = "uuid"
+	requestIDFormatBase62 = "base62"
+
+	base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	base62IDLength = 16
+)
+
+// clientRequestIDPattern limits which client-supplied ids are passed
+// through, so log lines cannot be forged or bloated.
+var clientRequestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// loadRequestIDFormat reads REQUEST_ID_FORMAT, defaulting to uuid.
+func loadRequestIDFormat() (string, error) {
+	format := os.Getenv("REQUEST_ID_FORMAT")
+	switch format {
+	case "":
+		return requestIDFormatUUID, nil
+	case requestIDFormatUUID, requestIDFormatBase62:
+		return format, nil
+	default:
+		return "", fmt.Errorf("REQUEST_ID_FORMAT must be %q or %q", requestIDFormatUUID, requestIDFormatBase62)
+	}
+}
+
+// newBase62ID returns a random id of base62IDLength characters, about 95
+// bits of randomness.
+func newBase62ID() string {
+	b := make([]byte, base62IDLength)
+	limit := big.NewInt(int64(len(base62Alphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			panic(err)
+		}
+		b[i] = base62Alphabet[n.Int64()]
+	}
+	return string(b)
+}
+
+// requestID tags each request with an id, reusing a well-formed
+// X-Request-ID from the client or generating one in the given format. The
+// id is echoed in the response header and stored on the context.
+//
+// SQLite has no session setting to carry the id into its own logs, so it
+// is not propagated to the database.
+func requestID(format string) gin.HandlerFunc {
+	generate := newPublicID
+	if format == requestIDFormatBase62 {
+		generate = newBase62ID
+	}
+
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !clientRequestIDPattern.MatchString(id) {
+			id = generate()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(requestIDHeader, id)
+		c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDFormat(t *testing.T) {
+	base62 := regexp.MustCompile(`^[0-9A-Za-z]{16}$`)
+	for format, pattern := range map[string]*regexp.Regexp{
+		"":                    uuidPattern,
+		requestIDFormatUUID:   uuidPattern,
+		requestIDFormatBase62: base62,
+	} {
+		router := newTestServer(t, "REQUEST_ID_FORMAT="+format)
+		w := doRequest(router, http.MethodGet, "/ping", "")
+		if got := w.Header().Get(requestIDHeader); !pattern.MatchString(got) {
+			t.Errorf("format %q: X-Request-ID = %q", format, got)
+		}
+	}
+
+	t.Setenv("REQUEST_ID_FORMAT", "ulid")
+	if _, err := loadRequestIDFormat(); err == nil {
+		t.Error("loadRequestIDFormat accepted an unknown format")
+	}
+}
+
+func TestRequestIDFromClient(t *testing.T) {
+	router := newTestServer(t, "REQUEST_ID_FORMAT=base62")
+
+	w := doRequest(router, http.MethodGet, "/ping", "", "X-Request-ID: trace-42.a_b")
+	if got := w.Header().Get(requestIDHeader); got != "trace-42.a_b" {
+		t.Errorf("X-Request-ID = %q, want the client's id", got)
+	}
+
+	for _, bad := range []string{"has space", "new\\nline", strings.Repeat("x", 129)} {
+		w := doRequest(router, http.MethodGet, "/ping", "", "X-Request-ID: "+bad)
+		if got := w.Header().Get(requestIDHeader); got == bad || len(got) != base62IDLength {
+			t.Errorf("client id %q: X-Request-ID = %q, want a generated id", bad, got)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// retryAfter holds the Retry-After advice sent when the server turns a
+// request away, per reason. Each can be tuned through its env var.
+var retryAfter = struct {
+	// Streams is sent when the streaming connection limit is reached.
+	Streams time.Duration
+	// Timeout is sent when a request runs past its deadline.
+	Timeout time.Duration
+	// Unavailable is sent by readiness checks that fail.
+	Unavailable time.Duration
+	// RateLimit is the least a rate limited client is told to wait; the
+	// time until its next token is used when that is longer.
+	RateLimit time.Duration
+}{}
+
+func initRetryAfter() {
+	retryAfter.Streams = envDuration("RETRY_AFTER_STREAMS", 5*time.Second)
+	retryAfter.Timeout = envDuration("RETRY_AFTER_TIMEOUT", time.Second)
+	retryAfter.Unavailable = envDuration("RETRY_AFTER_UNAVAILABLE", 30*time.Second)
+	retryAfter.RateLimit = envDuration("RETRY_AFTER_RATE_LIMIT", 0)
+}
+
+// setRetryAfter writes d as a Retry-After header in whole seconds, rounding
+// up and never below one second.
+func setRetryAfter(c *gin.Context, d time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(max(int(math.Ceil(d.Seconds())), 1)))
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSetRetryAfter(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                       "1",
+		300 * time.Millisecond:  "1",
+		1500 * time.Millisecond: "2",
+		time.Minute:             "60",
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		setRetryAfter(c, d)
+		if got := w.Header().Get("Retry-After"); got != want {
+			t.Errorf("setRetryAfter(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestRetryAfterConfig(t *testing.T) {
+	router := newTestServer(t, "ADMIN_TOKEN=secret", "RETRY_AFTER_UNAVAILABLE=45s", "RATE_LIMITS=/ping=1/1h,/time=1/1s", "RETRY_AFTER_RATE_LIMIT=90s")
+
+	t.Setenv("TITLE_CASE", "bogus")
+	w := doRequest(router, http.MethodGet, "/admin/preflight", "", "Authorization: Bearer secret")
+	expectStatus(t, w, http.StatusServiceUnavailable)
+	if got := w.Header().Get("Retry-After"); got != "45" {
+		t.Errorf("preflight Retry-After = %q, want 45", got)
+	}
+
+	// The configured value is a floor; a longer wait for the next token wins.
+	for path, want := range map[string]string{"/ping": "3600", "/time": "90"} {
+		doRequest(router, http.MethodGet, path, "")
+		w := doRequest(router, http.MethodGet, path, "")
+		expectStatus(t, w, http.StatusTooManyRequests)
+		if got := w.Header().Get("Retry-After"); got != want {
+			t.Errorf("%s Retry-After = %q, want %s", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// defaultRule fills in fields a client left empty when a task matches its
+// conditions. For example, this gives tasks created or moved to
+// in_progress without a due date one three days out:
+//
+//	[{"when": {"status": "in_progress"}, "set": {"due_in": "3d"}}]
+//
+// Conditions compare exactly and all must hold; an empty When matches every
+// task. On update a rule applies only when the change makes the task match,
+// so a field cleared on a task that already matched stays cleared. Set only
+// fills fields that are empty: no due date, no assignee, a priority of 0 or
+// no tags. Rules run in order, so when several match, the first to set a
+// field wins.
+type defaultRule struct {
+	When struct {
+		Status   *string `json:"status"`
+		Assignee *string `json:"assignee"`
+		Priority *int    `json:"priority"`
+	} `json:"when"`
+	Set struct {
+		DueIn    string   `json:"due_in"`
+		Assignee string   `json:"assignee"`
+		Priority int      `json:"priority"`
+		Tags     []string `json:"tags"`
+	} `json:"set"`
+
+	dueIn time.Duration
+}
+
+// defaultRules is loaded from DEFAULT_RULES_FILE or DEFAULT_RULES_JSON.
+var defaultRules []defaultRule
+
+func loadDefaultRules() ([]defaultRule, error) {
+	var data []byte
+	if path := os.Getenv("DEFAULT_RULES_FILE"); path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("read default rules file: %w", err)
+		}
+	} else if raw := os.Getenv("DEFAULT_RULES_JSON"); raw != "" {
+		data = []byte(raw)
+	} else {
+		return nil, nil
+	}
+
+	var rules []defaultRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse default rules: %w", err)
+	}
+	for i := range rules {
+		r := &rules[i]
+		if r.Set.DueIn == "" && r.Set.Assignee == "" && r.Set.Priority == 0 && len(r.Set.Tags) == 0 {
+			return nil, fmt.Errorf("default rule %d sets nothing", i+1)
+		}
+		if r.Set.DueIn != "" {
+			d, err := parseDays(r.Set.DueIn)
+			if err != nil {
+				return nil, fmt.Errorf("default rule %d: due_in must be a duration such as 3d", i+1)
+			}
+			r.dueIn = d
+		}
+		if r.Set.Priority != 0 && !validPriority(r.Set.Priority) {
+			return nil, fmt.Errorf("default rule %d: priority must be between %d and %d", i+1, minPriority, maxPriority)
+		}
+		if r.When.Status != nil && !currentWorkflow().IsStatus(*r.When.Status) {
+			return nil, fmt.Errorf("default rule %d: unknown status %q", i+1, *r.When.Status)
+		}
+		if r.When.Priority != nil && !validPriority(*r.When.Priority) {
+			return nil, fmt.Errorf("default rule %d: condition priority must be between %d and %d", i+1, minPriority, maxPriority)
+		}
+		r.Set.Tags = normalizeTags(r.Set.Tags)
+	}
+	return rules, nil
+}
+
+func initDefaultRules() error {
+	rules, err := loadDefaultRules()
+	if err != nil {
+		return err
+	}
+	defaultRules = rules
+	return nil
+}
+
+func (r defaultRule) matches(task Task) bool {
+	return (r.When.Status == nil || *r.When.Status == task.Status) &&
+		(r.When.Assignee == nil || *r.When.Assignee == task.Assignee) &&
+		(r.When.Priority == nil || *r.When.Priority == task.Priority)
+}
+
+// applyDefaultRules fills empty fields of task from the matching rules.
+// previous is the stored task on update and nil on create; rules it already
+// matched are skipped. It runs before validation so the results are
+// validated like client input.
+func applyDefaultRules(task, previous *Task, now time.Time) {
+	for _, r := range defaultRules {
+		if !r.matches(*task) || (previous != nil && r.matches(*previous)) {
+			continue
+		}
+		if task.DueDate == nil && r.Set.DueIn != "" {
+			due := now.UTC().Truncate(time.Second).Add(r.dueIn)
+			task.DueDate = &due
+		}
+		if task.Assignee == "" && r.Set.Assignee != "" {
+			task.Assignee = r.Set.Assignee
+		}
+		if task.Priority == minPriority && r.Set.Priority != 0 {
+			task.Priority = r.Set.Priority
+		}
+		if len(task.Tags) == 0 && len(r.Set.Tags) > 0 {
+			task.Tags = append([]string{}, r.Set.Tags...)
+		}
+	}
+}
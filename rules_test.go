@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+const testDefaultRules = `[
+	{"when": {"status": "in_progress"}, "set": {"due_in": "3d", "tags": ["active"]}},
+	{"when": {"status": "in_progress"}, "set": {"due_in": "10d", "priority": 2}}
+]`
+
+func TestDefaultRulesOnCreate(t *testing.T) {
+	router := newTestServer(t, "DEFAULT_RULES_JSON="+testDefaultRules)
+
+	before := time.Now().UTC().Truncate(time.Second)
+	task := createTestTask(t, router, `{"title":"started","status":"in_progress"}`)
+	if task.DueDate == nil || task.DueDate.Sub(before) < 72*time.Hour || task.DueDate.Sub(before) > 72*time.Hour+2*time.Second {
+		t.Errorf("due_date = %v, want three days out from the first rule", task.DueDate)
+	}
+	if task.Priority != 2 || !slices.Equal(task.Tags, []string{"active"}) {
+		t.Errorf("priority/tags = %d/%v, want both rules applied", task.Priority, task.Tags)
+	}
+
+	explicit := createTestTask(t, router, `{"title":"dated","status":"in_progress","due_date":"2027-01-01T00:00:00Z","priority":4}`)
+	if !explicit.DueDate.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)) || explicit.Priority != 4 {
+		t.Errorf("task = %+v, want client values kept", explicit)
+	}
+
+	if todo := createTestTask(t, router, `{"title":"later"}`); todo.DueDate != nil || todo.Priority != 0 {
+		t.Errorf("task = %+v, want no defaults outside in_progress", todo)
+	}
+}
+
+func TestDefaultRulesOnUpdate(t *testing.T) {
+	router := newTestServer(t, "DEFAULT_RULES_JSON="+testDefaultRules)
+	task := createTestTask(t, router, `{"title":"queued"}`)
+	path := taskLocation(task.ID)
+
+	w := doRequest(router, http.MethodPatch, path, `{"status":"in_progress"}`)
+	expectStatus(t, w, http.StatusOK)
+	if got := getTestTask(t, router, task.ID); got.DueDate == nil {
+		t.Fatal("moving to in_progress did not fill the due date")
+	}
+
+	// Clearing the date on a task that already matched sticks.
+	w = doRequest(router, http.MethodPatch, path, `{"due_date":null}`)
+	expectStatus(t, w, http.StatusOK)
+	if got := getTestTask(t, router, task.ID); got.DueDate != nil {
+		t.Errorf("due_date = %v, want it to stay cleared", got.DueDate)
+	}
+	w = doRequest(router, http.MethodPut, path, `{"title":"queued","status":"in_progress"}`)
+	expectStatus(t, w, http.StatusOK)
+	if got := getTestTask(t, router, task.ID); got.DueDate != nil {
+		t.Errorf("due_date = %v after a full update of a matching task", got.DueDate)
+	}
+}
+
+func TestLoadDefaultRulesRejectsBadRules(t *testing.T) {
+	for _, raw := range []string{
+		`{"when":{}}`,
+		`[{"when":{"status":"in_progress"},"set":{}}]`,
+		`[{"set":{"due_in":"soon"}}]`,
+		`[{"set":{"priority":9}}]`,
+		`[{"when":{"status":"archived"},"set":{"priority":1}}]`,
+	} {
+		t.Setenv("DEFAULT_RULES_JSON", raw)
+		if _, err := loadDefaultRules(); err == nil {
+			t.Errorf("loadDefaultRules(%s) succeeded", raw)
+		}
+	}
+}
+
+func TestDefaultRulesFile(t *testing.T) {
+	t.Setenv("DEFAULT_RULES_FILE", filepath.Join(t.TempDir(), "missing.json"))
+	if _, err := loadDefaultRules(); err == nil {
+		t.Error("loadDefaultRules succeeded with a missing file")
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+type securityHeader struct {
+	name   string
+	envKey string
+	value  string
+}
+
+// defaultSecurityHeaders are sent on every response unless SECURITY_HEADERS
+// is false. Each value can be replaced through its env var, or dropped by
+// setting the env var to "off".
+var defaultSecurityHeaders = []securityHeader{
+	{"X-Content-Type-Options", "SECURITY_X_CONTENT_TYPE_OPTIONS", "nosniff"},
+	{"X-Frame-Options", "SECURITY_X_FRAME_OPTIONS", "DENY"},
+	{"Referrer-Policy", "SECURITY_REFERRER_POLICY", "no-referrer"},
+	{"Content-Security-Policy", "SECURITY_CONTENT_SECURITY_POLICY", "default-src 'none'; frame-ancestors 'none'"},
+}
+
+func securityHeadersMiddleware() gin.HandlerFunc {
+	headers := make(map[string]string)
+	if envBool("SECURITY_HEADERS", true) {
+		for _, h := range defaultSecurityHeaders {
+			value := h.value
+			if override := os.Getenv(h.envKey); override != "" {
+				value = override
+			}
+			if value != "off" {
+				headers[h.name] = value
+			}
+		}
+	}
+
+	return func(c *gin.Context) {
+		for name, value := range headers {
+			c.Header(name, value)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		env  []string
+		want map[string]string
+	}{
+		{
+			name: "defaults",
+			want: map[string]string{"X-Content-Type-Options": "nosniff", "X-Frame-Options": "DENY"},
+		},
+		{
+			name: "override and drop",
+			env:  []string{"SECURITY_X_FRAME_OPTIONS=SAMEORIGIN", "SECURITY_REFERRER_POLICY=off"},
+			want: map[string]string{"X-Frame-Options": "SAMEORIGIN", "Referrer-Policy": ""},
+		},
+		{
+			name: "disabled",
+			env:  []string{"SECURITY_HEADERS=false"},
+			want: map[string]string{"X-Content-Type-Options": "", "Content-Security-Policy": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestServer(t, tt.env...)
+			w := doRequest(router, http.MethodGet, "/ping", "")
+			for name, want := range tt.want {
+				if got := w.Header().Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultStaleAge = 30 * 24 * time.Hour
+
+// getStaleTasks surfaces forgotten work: open tasks not updated within
+// ?older_than (30d by default), least recently updated first, narrowed by
+// the usual query filters and paginated.
+func getStaleTasks(c *gin.Context) {
+	age := defaultStaleAge
+	if raw := c.Query("older_than"); raw != "" {
+		d, err := parseDays(raw)
+		if err != nil || d <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "older_than must be a positive duration such as 30d or 12h",
+			})
+			return
+		}
+		age = d
+	}
+
+	filter, err := taskFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	filter.excludeStatuses = currentWorkflow().Done
+	page, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	where, args := filter.where()
+	if where == "" {
+		where = " WHERE COALESCE(updated_at, created_at) < ?"
+	} else {
+		where += " AND COALESCE(updated_at, created_at) < ?"
+	}
+	args = append(args, formatDBTime(time.Now().Add(-age)))
+
+	var total int
+	if err := db.QueryRowContext(c.Request.Context(), "SELECT COUNT(*) FROM tasks"+where, args...).Scan(&total); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count tasks",
+		})
+		return
+	}
+
+	rows, err := db.QueryContext(c.Request.Context(), "SELECT "+taskColumns+" FROM tasks"+where+" ORDER BY COALESCE(updated_at, created_at), id LIMIT ? OFFSET ?",
+		append(args, page.Limit, page.Offset)...)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+	defer rows.Close()
+
+	tasks := []Task{}
+	for rows.Next() {
+		var task Task
+		if err := scanTask(rows, &task); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan task",
+			})
+			return
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tasks",
+		})
+		return
+	}
+
+	if err := attachTags(requestDB(c), tasks); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tags",
+		})
+		return
+	}
+
+	setTotalCount(c, total)
+	respondList(c, tasks, pageMeta(total, page))
+}
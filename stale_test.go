@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGetStaleTasks(t *testing.T) {
+	router := newTestServer(t)
+	older := createTestTask(t, router, `{"title":"older"}`)
+	old := createTestTask(t, router, `{"title":"old","status":"in_progress"}`)
+	finished := createTestTask(t, router, `{"title":"finished","status":"done"}`)
+	createTestTask(t, router, `{"title":"fresh"}`)
+	for id, age := range map[int]time.Duration{
+		older.ID:    90 * 24 * time.Hour,
+		old.ID:      40 * 24 * time.Hour,
+		finished.ID: 90 * 24 * time.Hour,
+	} {
+		if _, err := db.Exec("UPDATE tasks SET updated_at = ? WHERE id = ?", formatDBTime(time.Now().Add(-age)), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		query string
+		want  []int
+	}{
+		{"", []int{older.ID, old.ID}},
+		{"?older_than=8w", []int{older.ID}},
+		{"?older_than=30d&status=in_progress", []int{old.ID}},
+	} {
+		w := doRequest(router, http.MethodGet, "/tasks/stale"+tt.query, "")
+		expectStatus(t, w, http.StatusOK)
+		var tasks []Task
+		decodeBody(t, w, &tasks)
+		var ids []int
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("stale%s = %v, want %v", tt.query, ids, tt.want)
+		}
+	}
+
+	for _, query := range []string{"?older_than=0d", "?older_than=soon", "?older_than=-3d"} {
+		w := doRequest(router, http.MethodGet, "/tasks/stale"+query, "")
+		expectStatus(t, w, http.StatusBadRequest)
+	}
+}
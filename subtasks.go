@@ -0,0 +1,71 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parentError reports a parent_id the client may not use. Other errors from
+// validateParent come from the database.
+type parentError struct {
+	msg string
+}
+
+func (e *parentError) Error() string {
+	return e.msg
+}
+
+// validateParent checks that parentID may become the parent of taskID: it
+// must exist and must not be the task itself or one of its descendants.
+// Pass a taskID of 0 for a task that does not exist yet. Invalid parents
+// are reported as a *parentError.
+func validateParent(q queryer, taskID int, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+
+	seen := make(map[int]bool)
+	next := sql.NullInt64{Int64: int64(*parentID), Valid: true}
+	for next.Valid {
+		id := int(next.Int64)
+		if id == taskID {
+			return &parentError{"a task cannot be its own ancestor"}
+		}
+		if seen[id] {
+			break
+		}
+		seen[id] = true
+
+		err := q.QueryRow("SELECT parent_id FROM tasks WHERE id = ? AND deleted_at IS NULL", id).Scan(&next)
+		if err == sql.ErrNoRows {
+			return &parentError{fmt.Sprintf("parent task %d not found", id)}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// respondParentError reports a validateParent failure: 400 for an invalid
+// parent, otherwise the database error.
+func respondParentError(c *gin.Context, err error) {
+	var parentErr *parentError
+	if errors.As(err, &parentErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": parentErr.Error(),
+		})
+		return
+	}
+	if respondTimedOut(c, err) {
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "failed to check parent task",
+	})
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// normalizeTags trims and lowercases tags, dropping empties and duplicates.
+// The result is sorted and never nil.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	out := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// setTaskTags replaces the tags on a task, creating any tag names not yet known.
+func setTaskTags(tx *sql.Tx, taskID int, tags []string) error {
+	if _, err := tx.Exec("DELETE FROM task_tags WHERE task_id = ?", taskID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := addTaskTag(tx, taskID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTaskTag attaches a single tag to a task; attaching it twice is a no-op.
+func addTaskTag(tx *sql.Tx, taskID int, tag string) error {
+	if _, err := tx.Exec("INSERT OR IGNORE INTO tags (name) VALUES (?)", tag); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`INSERT OR IGNORE INTO task_tags (task_id, tag_id)
+		SELECT ?, id FROM tags WHERE name = ?`, taskID, tag)
+	return err
+}
+
+// attachTags fills in the Tags field of each task.
+func attachTags(q queryer, tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	index := make(map[int]int, len(tasks))
+	args := make([]any, len(tasks))
+	for i := range tasks {
+		tasks[i].Tags = []string{}
+		index[tasks[i].ID] = i
+		args[i] = tasks[i].ID
+	}
+
+	rows, err := q.Query(`SELECT tt.task_id, t.name FROM task_tags tt
+		JOIN tags t ON t.id = tt.tag_id
+		WHERE tt.task_id IN (`+placeholders(len(tasks))+`)
+		ORDER BY t.name`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			taskID int
+			name   string
+		)
+		if err := rows.Scan(&taskID, &name); err != nil {
+			return err
+		}
+		i := index[taskID]
+		tasks[i].Tags = append(tasks[i].Tags, name)
+	}
+	return rows.Err()
+}
+
+type tagUsage struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// getTags lists tags by how many tasks use them, most used first. Tags no
+// task uses any more are left out unless ?include_unused=true.
+func getTags(c *gin.Context) {
+	page, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	usageSQL := `SELECT t.name, COUNT(tk.id) AS uses FROM tags t
+		LEFT JOIN task_tags tt ON tt.tag_id = t.id
+		LEFT JOIN tasks tk ON tk.id = tt.task_id AND tk.deleted_at IS NULL
+		GROUP BY t.id`
+	if c.Query("include_unused") != "true" {
+		usageSQL += " HAVING uses > 0"
+	}
+
+	var total int
+	if err := db.QueryRowContext(c.Request.Context(), "SELECT COUNT(*) FROM ("+usageSQL+")").Scan(&total); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count tags",
+		})
+		return
+	}
+
+	rows, err := db.QueryContext(c.Request.Context(), usageSQL+" ORDER BY uses DESC, t.name LIMIT ? OFFSET ?", page.Limit, page.Offset)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tags",
+		})
+		return
+	}
+	defer rows.Close()
+
+	tags := []tagUsage{}
+	for rows.Next() {
+		var t tagUsage
+		if err := rows.Scan(&t.Name, &t.Count); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan tag",
+			})
+			return
+		}
+		tags = append(tags, t)
+	}
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tags",
+		})
+		return
+	}
+
+	setTotalCount(c, total)
+	respondList(c, tags, pageMeta(total, page))
+}
+
+type tagStats struct {
+	Tag      string         `json:"tag"`
+	Total    int            `json:"total"`
+	Open     int            `json:"open"`
+	Overdue  int            `json:"overdue"`
+	ByStatus map[string]int `json:"by_status"`
+}
+
+// getTagStats counts the live tasks carrying a tag by status, for tag-scoped
+// boards. Every workflow status is listed, with zero when no task has it.
+func getTagStats(c *gin.Context) {
+	name := strings.ToLower(strings.TrimSpace(c.Param("tag")))
+
+	var tagID int
+	if err := db.QueryRowContext(c.Request.Context(), "SELECT id FROM tags WHERE name = ?", name).Scan(&tagID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "tag not found",
+			})
+		} else {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to fetch tag",
+			})
+		}
+		return
+	}
+
+	wf := currentWorkflow()
+	stats := tagStats{Tag: name, ByStatus: make(map[string]int, len(wf.Statuses))}
+	for _, s := range wf.Statuses {
+		stats.ByStatus[s] = 0
+	}
+
+	rows, err := db.QueryContext(c.Request.Context(), `SELECT t.status, COUNT(*),
+			SUM(CASE WHEN t.due_date IS NOT NULL AND t.due_date <= ? THEN 1 ELSE 0 END)
+		FROM tasks t JOIN task_tags tt ON tt.task_id = t.id
+		WHERE tt.tag_id = ? AND t.deleted_at IS NULL
+		GROUP BY t.status`, formatDBTime(time.Now()), tagID)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tag stats",
+		})
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			status      string
+			count, late int
+		)
+		if err := rows.Scan(&status, &count, &late); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan tag stats",
+			})
+			return
+		}
+		stats.ByStatus[status] = count
+		stats.Total += count
+		if !wf.IsDone(status) {
+			stats.Open += count
+			stats.Overdue += late
+		}
+	}
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tag stats",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
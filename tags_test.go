@@ -0,0 +1,70 @@
+package main
+
+import (
+	"maps"
+	"net/http"
+	"testing"
+)
+
+func TestGetTags(t *testing.T) {
+	router := newTestServer(t)
+	createTestTask(t, router, `{"title":"a","tags":["Backend","ops"]}`)
+	createTestTask(t, router, `{"title":"b","tags":["backend"]}`)
+	gone := createTestTask(t, router, `{"title":"c","tags":["legacy"]}`)
+	doRequest(router, http.MethodDelete, taskLocation(gone.ID), "")
+
+	w := doRequest(router, http.MethodGet, "/tags", "")
+	expectStatus(t, w, http.StatusOK)
+	var tags []tagUsage
+	decodeBody(t, w, &tags)
+	want := []tagUsage{{"backend", 2}, {"ops", 1}}
+	if len(tags) != len(want) || tags[0] != want[0] || tags[1] != want[1] {
+		t.Fatalf("tags = %+v, want %+v", tags, want)
+	}
+	if got := w.Header().Get("X-Total-Count"); got != "2" {
+		t.Errorf("X-Total-Count = %q, want 2", got)
+	}
+
+	w = doRequest(router, http.MethodGet, "/tags?include_unused=true", "")
+	expectStatus(t, w, http.StatusOK)
+	tags = nil
+	decodeBody(t, w, &tags)
+	if len(tags) != 3 || tags[2] != (tagUsage{"legacy", 0}) {
+		t.Fatalf("tags with unused = %+v, want legacy last with no uses", tags)
+	}
+}
+
+func TestGetTagStats(t *testing.T) {
+	router := newTestServer(t)
+	late := createTestTask(t, router, `{"title":"late","tags":["Work"]}`)
+	createTestTask(t, router, `{"title":"started","status":"in_progress","tags":["work","home"]}`)
+	finished := createTestTask(t, router, `{"title":"finished","status":"done","tags":["work"]}`)
+	gone := createTestTask(t, router, `{"title":"gone","tags":["work"]}`)
+	createTestTask(t, router, `{"title":"elsewhere","tags":["home"]}`)
+	doRequest(router, http.MethodDelete, taskLocation(gone.ID), "")
+	for _, id := range []int{late.ID, finished.ID} {
+		if _, err := db.Exec("UPDATE tasks SET due_date = ? WHERE id = ?", "2000-01-01T00:00:00Z", id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := doRequest(router, http.MethodGet, "/tags/WORK/stats", "")
+	expectStatus(t, w, http.StatusOK)
+	var stats tagStats
+	decodeBody(t, w, &stats)
+	if stats.Tag != "work" || stats.Total != 3 || stats.Open != 2 || stats.Overdue != 1 {
+		t.Errorf("stats = %+v, want 3 live tasks, 2 open and 1 overdue", stats)
+	}
+	if want := map[string]int{"todo": 1, "in_progress": 1, "done": 1}; !maps.Equal(stats.ByStatus, want) {
+		t.Errorf("by_status = %v, want %v", stats.ByStatus, want)
+	}
+
+	w = doRequest(router, http.MethodGet, "/tags/home/stats", "")
+	decodeBody(t, w, &stats)
+	if want := map[string]int{"todo": 1, "in_progress": 1, "done": 0}; !maps.Equal(stats.ByStatus, want) {
+		t.Errorf("home by_status = %v, want %v with zero done", stats.ByStatus, want)
+	}
+
+	w = doRequest(router, http.MethodGet, "/tags/nope/stats", "")
+	expectStatus(t, w, http.StatusNotFound)
+}
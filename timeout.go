@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestTimeout puts a deadline on the request context. Clients may ask
+// for one with X-Request-Timeout, e.g. "2s"; values that do not parse, are
+// not positive or exceed max are ignored in favour of def. A def of zero
+// means no deadline unless the client asks. Routes in untimed, such as event
+// streams, only get a deadline when the client sets one.
+func requestTimeout(def, max time.Duration, untimed ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(untimed))
+	for _, route := range untimed {
+		skip[route] = true
+	}
+
+	return func(c *gin.Context) {
+		timeout := def
+		if skip[c.FullPath()] {
+			timeout = 0
+		}
+		if raw := c.GetHeader("X-Request-Timeout"); raw != "" {
+			if d, err := time.ParseDuration(raw); err == nil && d > 0 && (max <= 0 || d <= max) {
+				timeout = d
+			}
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// respondTimedOut writes a 503 when err comes from the request deadline
+// passing, and reports whether it did.
+func respondTimedOut(c *gin.Context, err error) bool {
+	if !errors.Is(err, context.DeadlineExceeded) && !errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) {
+		return false
+	}
+	setRetryAfter(c, retryAfter.Timeout)
+	c.JSON(http.StatusServiceUnavailable, gin.H{
+		"error": "request timed out",
+	})
+	return true
+}
+
+// contextDB runs statements on db under a request context, so they stop
+// when the request deadline passes. It satisfies queryExecer.
+type contextDB struct {
+	ctx context.Context
+}
+
+// requestDB returns db bound to the request's context.
+func requestDB(c *gin.Context) contextDB {
+	return contextDB{ctx: c.Request.Context()}
+}
+
+func (d contextDB) Query(query string, args ...any) (*sql.Rows, error) {
+	return db.QueryContext(d.ctx, query, args...)
+}
+
+func (d contextDB) QueryRow(query string, args ...any) *sql.Row {
+	return db.QueryRowContext(d.ctx, query, args...)
+}
+
+func (d contextDB) Exec(query string, args ...any) (sql.Result, error) {
+	return db.ExecContext(d.ctx, query, args...)
+}
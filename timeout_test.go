@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestTimeoutHeader(t *testing.T) {
+	router := gin.New()
+	router.Use(requestTimeout(0, time.Second))
+	deadline := func(c *gin.Context) {
+		d, ok := c.Request.Context().Deadline()
+		if !ok {
+			c.String(http.StatusOK, "none")
+			return
+		}
+		c.String(http.StatusOK, time.Until(d).Round(100*time.Millisecond).String())
+	}
+	router.GET("/task", deadline)
+
+	for header, want := range map[string]string{
+		"":      "none",
+		"500ms": "500ms",
+		"2s":    "none",
+		"-1s":   "none",
+		"soon":  "none",
+	} {
+		w := doRequest(router, http.MethodGet, "/task", "", "X-Request-Timeout: "+header)
+		if got := w.Body.String(); got != want {
+			t.Errorf("X-Request-Timeout %q: deadline = %s, want %s", header, got, want)
+		}
+	}
+}
+
+func TestRequestTimeoutExceeded(t *testing.T) {
+	router := newTestServer(t, "RETRY_AFTER_TIMEOUT=3s")
+	createTestTask(t, router, `{"title":"a"}`)
+
+	w := doRequest(router, http.MethodGet, "/tasks", "", "X-Request-Timeout: 1ns")
+	expectStatus(t, w, http.StatusServiceUnavailable)
+	if got := w.Header().Get("Retry-After"); got != "3" {
+		t.Errorf("Retry-After = %q, want 3", got)
+	}
+
+	w = doRequest(router, http.MethodGet, "/tasks", "", "X-Request-Timeout: 5s")
+	expectStatus(t, w, http.StatusOK)
+}
+
+func TestRespondParentError(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want int
+	}{
+		{&parentError{"parent task 9 not found"}, http.StatusBadRequest},
+		{fmt.Errorf("query parent: %w", context.DeadlineExceeded), http.StatusServiceUnavailable},
+		{errors.New("database is locked"), http.StatusInternalServerError},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/task", nil)
+		respondParentError(c, tt.err)
+		if w.Code != tt.want {
+			t.Errorf("respondParentError(%v) = %d, want %d", tt.err, w.Code, tt.want)
+		}
+	}
+
+	router := newTestServer(t)
+	w := doRequest(router, http.MethodPost, "/task", `{"title":"orphan","parent_id":999}`)
+	expectStatus(t, w, http.StatusBadRequest)
+	var body map[string]string
+	decodeBody(t, w, &body)
+	if body["error"] != "parent task 999 not found" {
+		t.Errorf("error = %q, want the parent validation message", body["error"])
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timestamps are stored as UTC RFC 3339 strings with second precision so
+// that they compare correctly as plain text in SQL.
+const dbTimeLayout = time.RFC3339
+
+func formatDBTime(t time.Time) string {
+	return t.UTC().Format(dbTimeLayout)
+}
+
+// nullableDBTime converts an optional time into a value for a nullable column.
+func nullableDBTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatDBTime(*t)
+}
+
+// parseDBTime converts a nullable timestamp column back into a time.
+func parseDBTime(s sql.NullString) *time.Time {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+
+	t, err := time.Parse(dbTimeLayout, s.String)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// backfillTimestamps fills NULL created_at and updated_at values left by
+// databases from before those columns existed, so date sorts and filters
+// see every task. A task's created_at comes from its "created" audit entry
+// when there is one. Otherwise it is placed, in id order, one second apart
+// just before the earliest known creation time, or before now if there is
+// none. A missing updated_at is set to created_at. Rows that already have
+// timestamps are untouched, so running it again changes nothing.
+func backfillTimestamps() error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE tasks SET created_at = (
+			SELECT MIN(a.created_at) FROM task_audit a WHERE a.task_id = tasks.id AND a.action = 'created')
+		WHERE created_at IS NULL`); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query("SELECT id FROM tasks WHERE created_at IS NULL ORDER BY id")
+	if err != nil {
+		return err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(ids) > 0 {
+		var earliest sql.NullString
+		if err := tx.QueryRow("SELECT MIN(created_at) FROM tasks").Scan(&earliest); err != nil {
+			return err
+		}
+		base := time.Now().UTC().Truncate(time.Second)
+		if t := parseDBTime(earliest); t != nil {
+			base = *t
+		}
+		for i, id := range ids {
+			at := base.Add(-time.Duration(len(ids)-i) * time.Second)
+			if _, err := tx.Exec("UPDATE tasks SET created_at = ? WHERE id = ?", formatDBTime(at), id); err != nil {
+				return err
+			}
+		}
+		log.Printf("backfilled created_at on %d tasks", len(ids))
+	}
+
+	if _, err := tx.Exec("UPDATE tasks SET updated_at = created_at WHERE updated_at IS NULL"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// serverLocation is the timezone used for calendar-relative queries such as
+// "today". It follows the process timezone, set through TZ.
+func serverLocation() *time.Location {
+	return time.Local
+}
+
+// periodStart returns the start of the calendar period containing now, in
+// the server timezone. Weeks start on Monday.
+func periodStart(period string, now time.Time) (time.Time, bool) {
+	now = now.In(serverLocation())
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+
+	switch period {
+	case "today":
+		return today, true
+	case "week":
+		daysSinceMonday := (int(today.Weekday()) + 6) % 7
+		return today.AddDate(0, 0, -daysSinceMonday), true
+	case "month":
+		return time.Date(y, m, 1, 0, 0, 0, 0, now.Location()), true
+	}
+	return time.Time{}, false
+}
+
+// getServerTime reports the server clock and timezone so clients can align
+// relative date queries such as "due today" with the server's view.
+func getServerTime(c *gin.Context) {
+	now := time.Now().In(serverLocation())
+	name, offset := now.Zone()
+	c.JSON(http.StatusOK, gin.H{
+		"time":               now.Format(time.RFC3339),
+		"utc":                now.UTC().Format(time.RFC3339),
+		"unix":               now.Unix(),
+		"timezone":           serverLocation().String(),
+		"zone":               name,
+		"utc_offset_seconds": offset,
+	})
+}
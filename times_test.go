@@ -0,0 +1,114 @@
+package main
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGetServerTime(t *testing.T) {
+	router := newTestServer(t)
+	before := time.Now().Unix()
+	w := doRequest(router, http.MethodGet, "/time", "")
+	expectStatus(t, w, http.StatusOK)
+	var resp struct {
+		Time             string `json:"time"`
+		UTC              string `json:"utc"`
+		Unix             int64  `json:"unix"`
+		Timezone         string `json:"timezone"`
+		UTCOffsetSeconds int    `json:"utc_offset_seconds"`
+	}
+	decodeBody(t, w, &resp)
+
+	if resp.Unix < before || resp.Unix > time.Now().Unix() {
+		t.Errorf("unix = %d, want the current time", resp.Unix)
+	}
+	local, err := time.Parse(time.RFC3339, resp.Time)
+	if err != nil {
+		t.Fatalf("time %q is not RFC 3339: %v", resp.Time, err)
+	}
+	utc, err := time.Parse(time.RFC3339, resp.UTC)
+	if err != nil || !utc.Equal(local) || local.Unix() != resp.Unix {
+		t.Errorf("time = %s, utc = %s, unix = %d; want the same instant", resp.Time, resp.UTC, resp.Unix)
+	}
+	if _, offset := local.Zone(); offset != resp.UTCOffsetSeconds {
+		t.Errorf("utc_offset_seconds = %d, want %d", resp.UTCOffsetSeconds, offset)
+	}
+	if resp.Timezone != serverLocation().String() {
+		t.Errorf("timezone = %q, want %q", resp.Timezone, serverLocation())
+	}
+}
+
+func TestBackfillTimestamps(t *testing.T) {
+	router := newTestServer(t)
+	first := createTestTask(t, router, `{"title":"first"}`)
+	audited := createTestTask(t, router, `{"title":"audited"}`)
+	last := createTestTask(t, router, `{"title":"last"}`)
+	var auditedAt string
+	if err := db.QueryRow("SELECT created_at FROM task_audit WHERE task_id = ? AND action = 'created'", audited.ID).Scan(&auditedAt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("UPDATE tasks SET created_at = NULL, updated_at = NULL"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("DELETE FROM task_audit WHERE task_id IN (?, ?)", first.ID, last.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := func() map[int][2]string {
+		rows, err := db.Query("SELECT id, created_at, updated_at FROM tasks")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		got := make(map[int][2]string)
+		for rows.Next() {
+			var id int
+			var created, updated string
+			if err := rows.Scan(&id, &created, &updated); err != nil {
+				t.Fatalf("scan after backfill: %v", err)
+			}
+			got[id] = [2]string{created, updated}
+		}
+		return got
+	}
+
+	if err := backfillTimestamps(); err != nil {
+		t.Fatal(err)
+	}
+	got := snapshot()
+	if got[audited.ID][0] != auditedAt {
+		t.Errorf("audited created_at = %q, want the audit time %q", got[audited.ID][0], auditedAt)
+	}
+	for id, ts := range got {
+		if ts[1] != ts[0] {
+			t.Errorf("task %d updated_at = %q, want created_at %q", id, ts[1], ts[0])
+		}
+	}
+
+	rows, err := db.Query("SELECT id FROM tasks ORDER BY created_at")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		order = append(order, id)
+	}
+	rows.Close()
+	if want := []int{first.ID, last.ID, audited.ID}; !slices.Equal(order, want) {
+		t.Errorf("created_at order = %v, want %v: unaudited rows in id order before the earliest known time", order, want)
+	}
+
+	if err := backfillTimestamps(); err != nil {
+		t.Fatal(err)
+	}
+	if again := snapshot(); !maps.Equal(again, got) {
+		t.Errorf("second backfill changed timestamps: %v, was %v", again, got)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	titleCaseNone     = "none"
+	titleCaseSentence = "sentence"
+	titleCaseTitle    = "title"
+)
+
+// titleCaseMode is set from TITLE_CASE at startup.
+var titleCaseMode = titleCaseNone
+
+// loadTitleCase reads TITLE_CASE, defaulting to none.
+func loadTitleCase() (string, error) {
+	mode := os.Getenv("TITLE_CASE")
+	switch mode {
+	case "":
+		return titleCaseNone, nil
+	case titleCaseNone, titleCaseSentence, titleCaseTitle:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("TITLE_CASE must be %q, %q or %q", titleCaseNone, titleCaseSentence, titleCaseTitle)
+	}
+}
+
+func initTitleCase() error {
+	mode, err := loadTitleCase()
+	if err != nil {
+		return err
+	}
+	titleCaseMode = mode
+	return nil
+}
+
+// normalizeTitle applies the configured capitalisation to a task title.
+//
+// In title mode every word starts with a capital and the rest of the word is
+// lowercased; in sentence mode only the first word is capitalised. Words
+// written entirely in capitals, such as "API", are treated as acronyms and
+// left untouched in both modes. Whitespace is preserved as given.
+func normalizeTitle(title string) string {
+	if titleCaseMode == titleCaseNone {
+		return title
+	}
+
+	var b strings.Builder
+	b.Grow(len(title))
+
+	first := true
+	for len(title) > 0 {
+		i := strings.IndexFunc(title, func(r rune) bool { return !unicode.IsSpace(r) })
+		if i < 0 {
+			b.WriteString(title)
+			break
+		}
+		b.WriteString(title[:i])
+		title = title[i:]
+
+		end := strings.IndexFunc(title, unicode.IsSpace)
+		if end < 0 {
+			end = len(title)
+		}
+		word := title[:end]
+		title = title[end:]
+
+		capitalise := first || titleCaseMode == titleCaseTitle
+		b.WriteString(caseWord(word, capitalise))
+		first = false
+	}
+
+	return b.String()
+}
+
+func caseWord(word string, capitalise bool) string {
+	if isAcronym(word) {
+		return word
+	}
+
+	lower := strings.ToLower(word)
+	if !capitalise {
+		return lower
+	}
+
+	r, size := utf8.DecodeRuneInString(lower)
+	return string(unicode.ToTitle(r)) + lower[size:]
+}
+
+// isAcronym reports whether word has at least two letters, all uppercase.
+func isAcronym(word string) bool {
+	letters := 0
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			if !unicode.IsUpper(r) {
+				return false
+			}
+			letters++
+		}
+	}
+	return letters >= 2
+}
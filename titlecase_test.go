@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		mode, title, want string
+	}{
+		{titleCaseNone, "fix the API docs", "fix the API docs"},
+		{titleCaseSentence, "FIX the API Docs", "FIX the API docs"},
+		{titleCaseSentence, "éclair  recipe", "Éclair  recipe"},
+		{titleCaseTitle, "fix the API docs", "Fix The API Docs"},
+		{titleCaseTitle, "ÜBER straße", "ÜBER Straße"},
+	}
+	defer func(mode string) { titleCaseMode = mode }(titleCaseMode)
+	for _, tt := range tests {
+		titleCaseMode = tt.mode
+		if got := normalizeTitle(tt.title); got != tt.want {
+			t.Errorf("%s: normalizeTitle(%q) = %q, want %q", tt.mode, tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestTitleCaseOnCreateAndUpdate(t *testing.T) {
+	router := newTestServer(t, "TITLE_CASE=sentence")
+	task := createTestTask(t, router, `{"title":"review PR queue"}`)
+	if task.Title != "Review PR queue" {
+		t.Fatalf("created title = %q, want sentence case", task.Title)
+	}
+
+	w := doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"title":"SHIP it Today"}`)
+	expectStatus(t, w, http.StatusOK)
+	if got := getTestTask(t, router, task.ID); got.Title != "SHIP it today" {
+		t.Errorf("updated title = %q, want sentence case", got.Title)
+	}
+}
+
+func TestLoadTitleCaseRejectsUnknownMode(t *testing.T) {
+	t.Setenv("TITLE_CASE", "upper")
+	if _, err := loadTitleCase(); err == nil {
+		t.Fatal("loadTitleCase accepted an unknown mode")
+	}
+}
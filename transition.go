@@ -0,0 +1,162 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// transitionIssue is a rule a status change would break, or a side effect
+// the caller should confirm.
+type transitionIssue struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	TaskIDs []int  `json:"task_ids,omitempty"`
+}
+
+type transitionPreview struct {
+	TaskID     int               `json:"task_id"`
+	From       string            `json:"from"`
+	To         string            `json:"to"`
+	Allowed    bool              `json:"allowed"`
+	Violations []transitionIssue `json:"violations"`
+	Warnings   []transitionIssue `json:"warnings"`
+	Affected   []int             `json:"affected"`
+}
+
+// previewTransition works out what moving task to status would do without
+// changing anything. Violations make the move fail; warnings do not.
+func previewTransition(q queryer, wf *Workflow, task Task, to string) (transitionPreview, error) {
+	p := transitionPreview{
+		TaskID:     task.ID,
+		From:       task.Status,
+		To:         to,
+		Violations: []transitionIssue{},
+		Warnings:   []transitionIssue{},
+		Affected:   []int{},
+	}
+
+	switch {
+	case !wf.IsStatus(to):
+		p.Violations = append(p.Violations, transitionIssue{
+			Rule:    "unknown_status",
+			Message: fmt.Sprintf("%q is not a workflow status", to),
+		})
+	case task.InferStatus && to != task.Status:
+		p.Violations = append(p.Violations, transitionIssue{
+			Rule:    "status_inferred",
+			Message: "status is inferred from subtasks",
+		})
+	case !wf.CanTransition(task.Status, to):
+		p.Violations = append(p.Violations, transitionIssue{
+			Rule:    "workflow",
+			Message: fmt.Sprintf("cannot move task from %q to %q", task.Status, to),
+		})
+	}
+
+	if wf.IsDone(to) && !wf.IsDone(task.Status) {
+		blockers, err := openDependencyIDs(q, task.ID)
+		if err != nil {
+			return p, err
+		}
+		if len(blockers) > 0 {
+			p.Violations = append(p.Violations, transitionIssue{
+				Rule:    "dependency",
+				Message: fmt.Sprintf("%d dependencies are not done", len(blockers)),
+				TaskIDs: blockers,
+			})
+		}
+
+		open, err := openSubtaskIDs(q, wf, task.ID)
+		if err != nil {
+			return p, err
+		}
+		if len(open) > 0 {
+			p.Warnings = append(p.Warnings, transitionIssue{
+				Rule:    "open_subtasks",
+				Message: fmt.Sprintf("%d subtasks are not done", len(open)),
+				TaskIDs: open,
+			})
+		}
+	}
+
+	p.Allowed = len(p.Violations) == 0
+	if p.Allowed && to != task.Status {
+		affected, err := affectedAncestors(q, wf, task, to)
+		if err != nil {
+			return p, err
+		}
+		p.Affected = append(p.Affected, affected...)
+	}
+	return p, nil
+}
+
+func openSubtaskIDs(q queryer, wf *Workflow, parentID int) ([]int, error) {
+	rows, err := q.Query("SELECT id, status FROM tasks WHERE parent_id = ? AND deleted_at IS NULL ORDER BY id", parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var (
+			id     int
+			status string
+		)
+		if err := rows.Scan(&id, &status); err != nil {
+			return nil, err
+		}
+		if !wf.IsDone(status) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, rows.Err()
+}
+
+// getTransitionPreview answers GET /task/:id/transition-preview?to=status.
+func getTransitionPreview(c *gin.Context) {
+	taskID, ok := taskIDParam(c)
+	if !ok {
+		return
+	}
+	to := c.Query("to")
+	if to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "to is required",
+		})
+		return
+	}
+
+	task, err := fetchTask(requestDB(c), taskID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "task not found",
+			})
+		} else {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to fetch task",
+			})
+		}
+		return
+	}
+
+	preview, err := previewTransition(requestDB(c), currentWorkflow(), task, to)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to preview transition",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
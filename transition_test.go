@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func getTestPreview(t *testing.T, router http.Handler, id int, to string) transitionPreview {
+	t.Helper()
+	w := doRequest(router, http.MethodGet, taskLocation(id)+"/transition-preview?to="+to, "")
+	expectStatus(t, w, http.StatusOK)
+	var p transitionPreview
+	decodeBody(t, w, &p)
+	return p
+}
+
+func previewRules(issues []transitionIssue) []string {
+	rules := make([]string, len(issues))
+	for i, issue := range issues {
+		rules[i] = issue.Rule
+	}
+	return rules
+}
+
+func TestTransitionPreviewAffectedParents(t *testing.T) {
+	router := newTestServer(t)
+	grandparent := createTestTask(t, router, `{"title":"grandparent","infer_status":true}`)
+	parent := createTestTask(t, router, fmt.Sprintf(`{"title":"parent","infer_status":true,"parent_id":%d}`, grandparent.ID))
+	child := createTestTask(t, router, fmt.Sprintf(`{"title":"child","parent_id":%d}`, parent.ID))
+
+	p := getTestPreview(t, router, child.ID, "done")
+	if !p.Allowed || !slices.Equal(p.Affected, []int{parent.ID, grandparent.ID}) {
+		t.Fatalf("preview = %+v, want allowed with both ancestors affected", p)
+	}
+	if got := getTestTask(t, router, child.ID); got.Status != "todo" {
+		t.Errorf("preview changed the task status to %q", got.Status)
+	}
+
+	p = getTestPreview(t, router, parent.ID, "done")
+	if p.Allowed || !slices.Contains(previewRules(p.Violations), "status_inferred") {
+		t.Errorf("inferred preview = %+v, want a status_inferred violation", p)
+	}
+	p = getTestPreview(t, router, child.ID, "archived")
+	if p.Allowed || !slices.Contains(previewRules(p.Violations), "unknown_status") || len(p.Affected) != 0 {
+		t.Errorf("unknown status preview = %+v, want an unknown_status violation", p)
+	}
+}
+
+func TestTransitionPreviewBlockers(t *testing.T) {
+	router := newTestServer(t)
+	task := createTestTask(t, router, `{"title":"task"}`)
+	sub := createTestTask(t, router, fmt.Sprintf(`{"title":"sub","parent_id":%d}`, task.ID))
+	blocker := createTestTask(t, router, `{"title":"blocker"}`)
+	w := doRequest(router, http.MethodPost, taskLocation(task.ID)+"/dependencies", fmt.Sprintf(`{"depends_on":%d}`, blocker.ID))
+	expectStatus(t, w, http.StatusCreated)
+
+	p := getTestPreview(t, router, task.ID, "done")
+	if p.Allowed || len(p.Violations) != 1 || p.Violations[0].Rule != "dependency" || !slices.Equal(p.Violations[0].TaskIDs, []int{blocker.ID}) {
+		t.Errorf("violations = %+v, want the open dependency", p.Violations)
+	}
+	if len(p.Warnings) != 1 || p.Warnings[0].Rule != "open_subtasks" || !slices.Equal(p.Warnings[0].TaskIDs, []int{sub.ID}) {
+		t.Errorf("warnings = %+v, want the open subtask", p.Warnings)
+	}
+
+	w = doRequest(router, http.MethodGet, taskLocation(task.ID)+"/transition-preview", "")
+	expectStatus(t, w, http.StatusBadRequest)
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getTrash lists the caller's soft-deleted tasks, most recently deleted
+// first. Admins see everyone's.
+func getTrash(c *gin.Context) {
+	page, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	where := " WHERE deleted_at IS NOT NULL"
+	owned, args := ownerCondition(c, "")
+	if owned != "" {
+		where += " AND " + owned
+	}
+
+	var total int
+	if err := db.QueryRowContext(c.Request.Context(), "SELECT COUNT(*) FROM tasks"+where, args...).Scan(&total); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count deleted tasks",
+		})
+		return
+	}
+
+	rows, err := db.QueryContext(c.Request.Context(), "SELECT "+taskColumns+" FROM tasks"+where+" ORDER BY deleted_at DESC, id DESC LIMIT ? OFFSET ?",
+		append(args, page.Limit, page.Offset)...)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch deleted tasks",
+		})
+		return
+	}
+	defer rows.Close()
+
+	tasks := []Task{}
+	for rows.Next() {
+		var task Task
+		if err := scanTask(rows, &task); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan task",
+			})
+			return
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch deleted tasks",
+		})
+		return
+	}
+
+	if err := attachTags(requestDB(c), tasks); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch tags",
+		})
+		return
+	}
+
+	setTotalCount(c, total)
+	respondList(c, tasks, pageMeta(total, page))
+}
+
+// restoreTask brings a soft-deleted task back from the trash. Only its
+// owner may restore it.
+func restoreTask(c *gin.Context) {
+	taskID, ok := taskIDParam(c)
+	if !ok {
+		return
+	}
+
+	tx, err := db.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	var owner string
+	err = tx.QueryRow("SELECT owner FROM tasks WHERE id = ? AND deleted_at IS NOT NULL", taskID).Scan(&owner)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "deleted task not found",
+		})
+		return
+	}
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch deleted task",
+		})
+		return
+	}
+	if !canManage(owner, requestActor(c)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "task is owned by someone else",
+		})
+		return
+	}
+
+	if _, err := tx.Exec("UPDATE tasks SET deleted_at = NULL, updated_at = ? WHERE id = ?",
+		formatDBTime(time.Now()), taskID); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to restore task",
+		})
+		return
+	}
+
+	if err := recordAudit(tx, taskID, "restored", requestActor(c), nil); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to record audit entry",
+		})
+		return
+	}
+	if err := refreshParentStatus(tx, requestActor(c), taskID); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to update parent status",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to commit transaction",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "task restored successfully",
+	})
+}
+
+// canManage reports whether actor may restore or merge a task owned by
+// owner. Tasks with no recorded owner may be managed by anyone.
+func canManage(owner, actor string) bool {
+	return owner == "" || owner == actor
+}
+
+// maxBulkRestoreIDs caps how many ids one bulk restore may name.
+const maxBulkRestoreIDs = 500
+
+type bulkRestoreRequest struct {
+	IDs []taskRef `json:"ids"`
+}
+
+// bulkRestoreTasks restores many soft-deleted tasks at once. Ids that are
+// not in the trash are skipped, as are tasks owned by someone other than
+// the caller. UUIDs that match no task are reported as unknown.
+func bulkRestoreTasks(c *gin.Context) {
+	var req bulkRestoreRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if len(req.IDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "ids must name at least one task",
+		})
+		return
+	}
+	if len(req.IDs) > maxBulkRestoreIDs {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "too many ids",
+			"limit": maxBulkRestoreIDs,
+		})
+		return
+	}
+
+	tx, err := db.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	var (
+		ids     = make([]int, 0, len(req.IDs))
+		unknown = []string{}
+	)
+	for _, ref := range req.IDs {
+		id, err := resolveTaskID(tx, string(ref))
+		switch {
+		case err == errInvalidTaskID:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid task ID",
+				"id":    string(ref),
+			})
+			return
+		case err == sql.ErrNoRows:
+			unknown = append(unknown, string(ref))
+			continue
+		case err != nil:
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to fetch deleted tasks",
+			})
+			return
+		}
+		ids = append(ids, id)
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := tx.Query("SELECT id, owner FROM tasks WHERE deleted_at IS NOT NULL AND id IN ("+placeholders(len(args))+")", args...)
+	if err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch deleted tasks",
+		})
+		return
+	}
+	owners := make(map[int]string)
+	for rows.Next() {
+		var (
+			id    int
+			owner string
+		)
+		if err := rows.Scan(&id, &owner); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to scan task",
+			})
+			return
+		}
+		owners[id] = owner
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch deleted tasks",
+		})
+		return
+	}
+
+	actor := requestActor(c)
+	now := formatDBTime(time.Now())
+	var (
+		restored = []int{}
+		skipped  = []int{}
+		notOwned = []int{}
+		seen     = make(map[int]bool, len(ids))
+	)
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		owner, deleted := owners[id]
+		switch {
+		case !deleted:
+			skipped = append(skipped, id)
+			continue
+		case !canManage(owner, actor):
+			notOwned = append(notOwned, id)
+			continue
+		}
+
+		if _, err := tx.Exec("UPDATE tasks SET deleted_at = NULL, updated_at = ? WHERE id = ?", now, id); err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to restore task",
+			})
+			return
+		}
+		if err := recordAudit(tx, id, "restored", actor, nil); err != nil {
+			if respondTimedOut(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to record audit entry",
+			})
+			return
+		}
+		restored = append(restored, id)
+	}
+	if err := refreshParentStatus(tx, actor, restored...); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to update parent status",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		if respondTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to commit transaction",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"restored":     len(restored),
+		"restored_ids": restored,
+		"skipped":      skipped,
+		"not_owned":    notOwned,
+		"unknown":      unknown,
+	})
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGetTrash(t *testing.T) {
+	router := newTestServer(t)
+	kept := createTestTask(t, router, `{"title":"kept"}`)
+	first := createTestTask(t, router, `{"title":"first deleted"}`)
+	second := createTestTask(t, router, `{"title":"second deleted"}`)
+	for _, task := range []Task{first, second} {
+		w := doRequest(router, http.MethodDelete, taskLocation(task.ID), "")
+		expectStatus(t, w, http.StatusOK)
+	}
+
+	w := doRequest(router, http.MethodGet, "/tasks/trash", "")
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Header().Get("X-Total-Count"); got != "2" {
+		t.Errorf("X-Total-Count = %q, want 2", got)
+	}
+	var tasks []Task
+	decodeBody(t, w, &tasks)
+	if len(tasks) != 2 || tasks[0].ID != second.ID || tasks[1].ID != first.ID {
+		t.Fatalf("trash = %+v, want the deleted tasks newest first", tasks)
+	}
+	for _, task := range tasks {
+		if task.DeletedAt == nil {
+			t.Errorf("task %d has no deleted_at", task.ID)
+		}
+	}
+
+	w = doRequest(router, http.MethodGet, "/tasks", "")
+	var live []Task
+	decodeBody(t, w, &live)
+	if len(live) != 1 || live[0].ID != kept.ID {
+		t.Errorf("live tasks = %+v, want only the kept task", live)
+	}
+}
+
+func TestGetTrashScopedToOwner(t *testing.T) {
+	router := newTestServer(t, "ADMIN_TOKEN=secret")
+	mine := createTestTask(t, router, `{"title":"mine"}`, "X-User: alice")
+	theirs := createTestTask(t, router, `{"title":"theirs"}`, "X-User: bob")
+	doRequest(router, http.MethodDelete, taskLocation(mine.ID), "", "X-User: alice")
+	doRequest(router, http.MethodDelete, taskLocation(theirs.ID), "", "X-User: bob")
+
+	for _, tt := range []struct {
+		header string
+		want   []int
+	}{
+		{"X-User: alice", []int{mine.ID}},
+		{"X-User: carol", nil},
+		{"Authorization: Bearer secret", []int{theirs.ID, mine.ID}},
+	} {
+		w := doRequest(router, http.MethodGet, "/tasks/trash", "", tt.header)
+		expectStatus(t, w, http.StatusOK)
+		var tasks []Task
+		decodeBody(t, w, &tasks)
+		var ids []int
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("trash for %q = %v, want %v", tt.header, ids, tt.want)
+		}
+		if got := w.Header().Get("X-Total-Count"); got != strconv.Itoa(len(tt.want)) {
+			t.Errorf("X-Total-Count for %q = %s, want %d", tt.header, got, len(tt.want))
+		}
+	}
+}
+
+func TestRestoreTask(t *testing.T) {
+	router := newTestServer(t)
+	task := createTestTask(t, router, `{"title":"mine"}`, "X-User: alice")
+	doRequest(router, http.MethodDelete, taskLocation(task.ID), "", "X-User: alice")
+
+	w := doRequest(router, http.MethodPost, taskLocation(task.ID)+"/restore", "", "X-User: bob")
+	expectStatus(t, w, http.StatusForbidden)
+
+	w = doRequest(router, http.MethodPost, taskLocation(task.ID)+"/restore", "", "X-User: alice")
+	expectStatus(t, w, http.StatusOK)
+	if got := getTestTask(t, router, task.ID); got.DeletedAt != nil {
+		t.Errorf("restored task still has deleted_at %v", got.DeletedAt)
+	}
+
+	w = doRequest(router, http.MethodPost, taskLocation(task.ID)+"/restore", "", "X-User: alice")
+	expectStatus(t, w, http.StatusNotFound)
+}
+
+func TestBulkRestoreTasks(t *testing.T) {
+	router := newTestServer(t)
+	live := createTestTask(t, router, `{"title":"live"}`, "X-User: alice")
+	deleted := createTestTask(t, router, `{"title":"deleted"}`, "X-User: alice")
+	bobs := createTestTask(t, router, `{"title":"bob's"}`, "X-User: bob")
+	for _, task := range []Task{deleted, bobs} {
+		doRequest(router, http.MethodDelete, taskLocation(task.ID), "")
+	}
+
+	body := fmt.Sprintf(`{"ids":[%d,%q,%d,%d,"00000000-0000-4000-8000-000000000000"]}`,
+		live.ID, strings.ToUpper(deleted.PublicID), deleted.ID, bobs.ID)
+	w := doRequest(router, http.MethodPost, "/tasks/bulk-restore", body, "X-User: alice")
+	expectStatus(t, w, http.StatusOK)
+	var resp struct {
+		Restored    int      `json:"restored"`
+		RestoredIDs []int    `json:"restored_ids"`
+		Skipped     []int    `json:"skipped"`
+		NotOwned    []int    `json:"not_owned"`
+		Unknown     []string `json:"unknown"`
+	}
+	decodeBody(t, w, &resp)
+	if resp.Restored != 1 || resp.RestoredIDs[0] != deleted.ID {
+		t.Errorf("restored = %v, want only %d", resp.RestoredIDs, deleted.ID)
+	}
+	if len(resp.Skipped) != 1 || resp.Skipped[0] != live.ID {
+		t.Errorf("skipped = %v, want the live task", resp.Skipped)
+	}
+	if len(resp.NotOwned) != 1 || resp.NotOwned[0] != bobs.ID {
+		t.Errorf("not_owned = %v, want bob's task", resp.NotOwned)
+	}
+	if len(resp.Unknown) != 1 {
+		t.Errorf("unknown = %v, want the unmatched UUID", resp.Unknown)
+	}
+	if got := getTestTask(t, router, deleted.ID); got.DeletedAt != nil {
+		t.Error("restored task is still deleted")
+	}
+
+	for _, body := range []string{`{"ids":[]}`, `{"ids":["nope"]}`} {
+		w := doRequest(router, http.MethodPost, "/tasks/bulk-restore", body)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
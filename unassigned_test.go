@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetUnassignedTasks(t *testing.T) {
+	router := newTestServer(t)
+	first := createTestTask(t, router, `{"title":"first"}`)
+	createTestTask(t, router, `{"title":"assigned","assignee":"alice"}`)
+	done := createTestTask(t, router, `{"title":"done","status":"done"}`)
+	last := createTestTask(t, router, `{"title":"last","assignee":""}`)
+
+	ids := func(path string) []int {
+		t.Helper()
+		w := doRequest(router, http.MethodGet, path, "")
+		expectStatus(t, w, http.StatusOK)
+		var tasks []Task
+		decodeBody(t, w, &tasks)
+		var ids []int
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	if got := ids("/tasks/unassigned"); len(got) != 3 || got[0] != first.ID || got[1] != done.ID || got[2] != last.ID {
+		t.Errorf("queue = %v, want the three unassigned tasks oldest first", got)
+	}
+	if got := ids("/tasks/unassigned?status=todo&limit=1&offset=1"); len(got) != 1 || got[0] != last.ID {
+		t.Errorf("second todo page = %v, want [%d]", got, last.ID)
+	}
+	if got := ids("/tasks?unassigned=true&status=done"); len(got) != 1 || got[0] != done.ID {
+		t.Errorf("filtered list = %v, want [%d]", got, done.ID)
+	}
+
+	w := doRequest(router, http.MethodGet, "/tasks/unassigned?assignee=alice", "")
+	expectStatus(t, w, http.StatusBadRequest)
+}
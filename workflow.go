@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"sync/atomic"
+	"syscall"
+)
+
+// Workflow describes the statuses a task may have and which status changes
+// are allowed between them.
+type Workflow struct {
+	Statuses    []string            `json:"statuses"`
+	Initial     string              `json:"initial"`
+	Done        []string            `json:"done"`
+	Transitions map[string][]string `json:"transitions"`
+}
+
+var defaultWorkflow = Workflow{
+	Statuses: []string{"todo", "in_progress", "done"},
+	Initial:  "todo",
+	Done:     []string{"done"},
+	Transitions: map[string][]string{
+		"todo":        {"in_progress", "done"},
+		"in_progress": {"todo", "done"},
+		"done":        {"todo", "in_progress"},
+	},
+}
+
+var workflow atomic.Pointer[Workflow]
+
+func currentWorkflow() *Workflow {
+	return workflow.Load()
+}
+
+func (w *Workflow) validate() error {
+	if len(w.Statuses) == 0 {
+		return errors.New("workflow must define at least one status")
+	}
+
+	seen := make(map[string]bool, len(w.Statuses))
+	for _, s := range w.Statuses {
+		if s == "" {
+			return errors.New("workflow status must not be empty")
+		}
+		if seen[s] {
+			return fmt.Errorf("duplicate workflow status %q", s)
+		}
+		seen[s] = true
+	}
+
+	if !seen[w.Initial] {
+		return fmt.Errorf("initial status %q is not a workflow status", w.Initial)
+	}
+	for _, s := range w.Done {
+		if !seen[s] {
+			return fmt.Errorf("done status %q is not a workflow status", s)
+		}
+	}
+	for from, targets := range w.Transitions {
+		if !seen[from] {
+			return fmt.Errorf("transition from unknown status %q", from)
+		}
+		for _, to := range targets {
+			if !seen[to] {
+				return fmt.Errorf("transition from %q to unknown status %q", from, to)
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsStatus reports whether status is defined by the workflow.
+func (w *Workflow) IsStatus(status string) bool {
+	return slices.Contains(w.Statuses, status)
+}
+
+// IsDone reports whether status counts as finished work.
+func (w *Workflow) IsDone(status string) bool {
+	return slices.Contains(w.Done, status)
+}
+
+// CanTransition reports whether a task may move from one status to another.
+// Tasks whose current status predates the workflow may move to any status,
+// so legacy rows are never stuck.
+func (w *Workflow) CanTransition(from, to string) bool {
+	if from == to || !w.IsStatus(from) {
+		return true
+	}
+	return slices.Contains(w.Transitions[from], to)
+}
+
+// loadWorkflow reads the workflow from WORKFLOW_FILE or WORKFLOW_JSON,
+// falling back to the built-in default when neither is set.
+func loadWorkflow() (*Workflow, error) {
+	var data []byte
+	if path := os.Getenv("WORKFLOW_FILE"); path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read workflow file: %w", err)
+		}
+	} else if raw := os.Getenv("WORKFLOW_JSON"); raw != "" {
+		data = []byte(raw)
+	} else {
+		w := defaultWorkflow
+		return &w, nil
+	}
+
+	var w Workflow
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("parse workflow: %w", err)
+	}
+	if err := w.validate(); err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func initWorkflow() error {
+	w, err := loadWorkflow()
+	if err != nil {
+		return err
+	}
+	workflow.Store(w)
+
+	if os.Getenv("WORKFLOW_FILE") != "" {
+		go reloadWorkflowOnSIGHUP()
+	}
+
+	return nil
+}
+
+// reloadWorkflowOnSIGHUP re-reads the workflow file whenever the process
+// receives SIGHUP. An invalid file is logged and the previous workflow kept.
+func reloadWorkflowOnSIGHUP() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+
+	for range sig {
+		w, err := loadWorkflow()
+		if err != nil {
+			log.Printf("workflow reload failed, keeping previous: %v", err)
+			continue
+		}
+		workflow.Store(w)
+		log.Printf("workflow reloaded with %d statuses", len(w.Statuses))
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+const reviewWorkflow = `{
+	"statuses": ["backlog", "review", "shipped"],
+	"initial": "backlog",
+	"done": ["shipped"],
+	"transitions": {"backlog": ["review"], "review": ["backlog", "shipped"]}
+}`
+
+func TestCustomWorkflow(t *testing.T) {
+	router := newTestServer(t, "WORKFLOW_JSON="+reviewWorkflow)
+
+	task := createTestTask(t, router, `{"title":"write docs"}`)
+	if task.Status != "backlog" {
+		t.Fatalf("status = %q, want the initial status backlog", task.Status)
+	}
+
+	w := doRequest(router, http.MethodPost, "/task", `{"title":"x","status":"todo"}`)
+	expectStatus(t, w, http.StatusBadRequest)
+
+	w = doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"status":"shipped"}`)
+	expectStatus(t, w, http.StatusConflict)
+
+	w = doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"status":"review"}`)
+	expectStatus(t, w, http.StatusOK)
+	w = doRequest(router, http.MethodPatch, taskLocation(task.ID), `{"status":"shipped"}`)
+	expectStatus(t, w, http.StatusOK)
+	if got := getTestTask(t, router, task.ID); got.Status != "shipped" || got.CompletedAt == nil {
+		t.Fatalf("got status %q, completed_at %v; want shipped and set", got.Status, got.CompletedAt)
+	}
+}
+
+func TestLoadWorkflowRejectsInvalidConfig(t *testing.T) {
+	for name, raw := range map[string]string{
+		"malformed":       `{`,
+		"no statuses":     `{"statuses": [], "initial": ""}`,
+		"unknown initial": `{"statuses": ["a"], "initial": "b"}`,
+		"unknown target":  `{"statuses": ["a"], "initial": "a", "transitions": {"a": ["b"]}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("WORKFLOW_JSON", raw)
+			if _, err := loadWorkflow(); err == nil {
+				t.Fatal("loadWorkflow accepted an invalid workflow")
+			}
+		})
+	}
+}